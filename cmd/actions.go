@@ -0,0 +1,45 @@
+/**
+ * Copyright 2018 Google LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the license.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specified language governing permissions and
+ * limitations under the License.
+ */
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/achew22/logbook/templater"
+)
+
+var actionsCommand = &command{
+	name:    "actions",
+	summary: "Lists the open action items by owner.",
+	help: `Lists every action item, written as "AI(owner): text", that hasn't been
+checked off, grouped by owner.`,
+	run: runActions,
+}
+
+func runActions(e *env, fs *flag.FlagSet) int {
+	if fs.NArg() != 0 {
+		return usageError(fs, "actions doesn't take any arguments")
+	}
+	entries, err := parse(e)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Unable to read the logbook: %v\n", err)
+		return 1
+	}
+	fmt.Print(templater.PrintActions(entries))
+	return 0
+}
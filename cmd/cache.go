@@ -0,0 +1,60 @@
+/**
+ * Copyright 2018 Google LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the license.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specified language governing permissions and
+ * limitations under the License.
+ */
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/achew22/logbook/parser"
+)
+
+var cacheCommand = &command{
+	name:    "cache",
+	args:    "rebuild",
+	summary: "Rebuilds the cache of parsed entries.",
+	help: `Parses every file in the logbook again, replacing the cache. Normally a file
+is only parsed again when its size or modification time changes, so rebuild
+the cache if a file changed without either of them changing. Use
+"logbook --no_cache <command>" to skip the cache for a single command.`,
+	run: runCache,
+}
+
+func runCache(e *env, fs *flag.FlagSet) int {
+	if fs.NArg() != 1 {
+		return usageError(fs, "cache takes one argument")
+	}
+	if fs.Arg(0) != "rebuild" {
+		return usageError(fs, "Unknown cache command %q", fs.Arg(0))
+	}
+
+	path, err := parser.DefaultCachePath(e.config)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Unable to find the cache directory: %v\n", err)
+		return 1
+	}
+
+	p := parser.New(e.config)
+	p.UseCache(path)
+	if err := p.RebuildCache(); err != nil {
+		fmt.Fprintf(os.Stderr, "Unable to rebuild the cache: %v\n", err)
+		return 1
+	}
+
+	fmt.Fprintf(os.Stderr, "Rebuilt the cache in %s\n", path)
+	return 0
+}
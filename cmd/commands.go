@@ -0,0 +1,201 @@
+/**
+ * Copyright 2018 Google LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the license.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specified language governing permissions and
+ * limitations under the License.
+ */
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/achew22/logbook/parser"
+)
+
+// command is a subcommand, like the "show" in "logbook show 2000-01-01".
+type command struct {
+	name string
+
+	// args describes the arguments in the usage, like "<date>".
+	args string
+
+	// summary is a one line description for the list of commands.
+	summary string
+
+	// help is the full description printed by "logbook help <command>".
+	help string
+
+	// setFlags adds the command's flags, if it has any, to fs.
+	setFlags func(fs *flag.FlagSet)
+
+	// run runs the command once its flags have been parsed and returns the
+	// exit code.
+	run func(e *env, fs *flag.FlagSet) int
+}
+
+var commands []*command
+
+func init() {
+	commands = []*command{
+		newCommand,
+		editCommand,
+		showCommand,
+		listCommand,
+		searchCommand,
+		lintCommand,
+		statsCommand,
+		actionsCommand,
+		perfCommand,
+		dumpCommand,
+		exportCommand,
+		importCommand,
+		serveCommand,
+		cacheCommand,
+		helpCommand,
+	}
+}
+
+func lookupCommand(name string) *command {
+	for _, c := range commands {
+		if c.name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+func (c *command) flagSet() *flag.FlagSet {
+	fs := flag.NewFlagSet(c.name, flag.ExitOnError)
+	if c.setFlags != nil {
+		c.setFlags(fs)
+	}
+	fs.Usage = func() {
+		c.usage(fs)
+	}
+	return fs
+}
+
+func (c *command) usage(fs *flag.FlagSet) {
+	out := fs.Output()
+	fmt.Fprintf(out, "Usage: logbook %s", c.name)
+	if c.setFlags != nil {
+		fmt.Fprintf(out, " [flags]")
+	}
+	if c.args != "" {
+		fmt.Fprintf(out, " %s", c.args)
+	}
+	fmt.Fprintf(out, "\n\n%s\n", c.help)
+	if c.setFlags != nil {
+		fmt.Fprintf(out, "\nFlags:\n")
+		fs.PrintDefaults()
+	}
+}
+
+// usageError reports that the command was invoked incorrectly.
+func usageError(fs *flag.FlagSet, format string, a ...interface{}) int {
+	fmt.Fprintf(os.Stderr, format+"\n\n", a...)
+	fs.Usage()
+	return 2
+}
+
+func usage() {
+	out := flag.CommandLine.Output()
+	fmt.Fprintf(out, "Usage: logbook [flags] [command] [arguments]\n\n")
+	fmt.Fprintf(out, "Without a command, generates today's entry like \"logbook new\".\n\n")
+	fmt.Fprintf(out, "Commands:\n")
+	for _, c := range commands {
+		fmt.Fprintf(out, "  %-8s %s\n", c.name, c.summary)
+	}
+	fmt.Fprintf(out, "\nFlags:\n")
+	flag.PrintDefaults()
+	fmt.Fprintf(out, "\nRun \"logbook help <command>\" for more about a command.\n")
+}
+
+var helpCommand = &command{
+	name:    "help",
+	args:    "[command]",
+	summary: "Describes a command.",
+	help:    "Describes a command, or lists every command if none is given.",
+	run:     runHelp,
+}
+
+func runHelp(e *env, fs *flag.FlagSet) int {
+	if fs.NArg() == 0 {
+		flag.CommandLine.SetOutput(os.Stdout)
+		usage()
+		return 0
+	}
+
+	c := lookupCommand(fs.Arg(0))
+	if c == nil {
+		fmt.Fprintf(os.Stderr, "Unknown command %q\n", fs.Arg(0))
+		return 1
+	}
+	cfs := c.flagSet()
+	cfs.SetOutput(os.Stdout)
+	cfs.Usage()
+	return 0
+}
+
+// parseDate parses a date given on the command line. Dates can be written
+// any way a reminder can, relative to today, as well as "today" and
+// "yesterday".
+func parseDate(e *env, s string) (parser.Date, error) {
+	switch strings.ToLower(s) {
+	case "today":
+		return e.today, nil
+	case "yesterday":
+		return parser.TimeToDate(e.today.ToTime().AddDate(0, 0, -1)), nil
+	}
+	return parser.ParseTimespec(e.today, s)
+}
+
+// entriesOnDisk returns the entries that were read from a file, oldest first.
+func entriesOnDisk(entries map[parser.Date]*parser.LogEntry) []*parser.LogEntry {
+	var out []*parser.LogEntry
+	for _, entry := range entries {
+		if entry.OnDisk {
+			out = append(out, entry)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Date.Before(out[j].Date)
+	})
+	return out
+}
+
+// parse parses the logbook, printing a warning for each file it had to skip.
+func parse(e *env) (map[parser.Date]*parser.LogEntry, error) {
+	entries, err := e.parser.Parse()
+	if warnings, ok := err.(parser.Warnings); ok {
+		for _, w := range warnings {
+			fmt.Fprintf(os.Stderr, "Warning: %s: %s\n", relPath(e, w.Path), w.Message)
+		}
+		return entries, nil
+	}
+	return entries, err
+}
+
+// relPath returns path relative to the logbook directory, which is how
+// entries are referred to in output.
+func relPath(e *env, path string) string {
+	rel, err := filepath.Rel(e.config.LogPath, path)
+	if err != nil {
+		return path
+	}
+	return rel
+}
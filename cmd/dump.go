@@ -0,0 +1,92 @@
+/**
+ * Copyright 2018 Google LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the license.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specified language governing permissions and
+ * limitations under the License.
+ */
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/achew22/logbook/parser"
+)
+
+var dumpFormat string
+
+var dumpCommand = &command{
+	name:    "dump",
+	summary: "Prints everything parsed from the logbook as JSON.",
+	help: `Prints everything parsed from the logbook as JSON, for tools like jq.
+
+With --format=json, the output is a single object keyed by date. With
+--format=jsonl, each entry is printed on its own line, oldest first. Either way
+the output is the same from run to run.`,
+	setFlags: func(fs *flag.FlagSet) {
+		fs.StringVar(&dumpFormat, "format", "json", "The format to print, either \"json\" or \"jsonl\".")
+	},
+	run: runDump,
+}
+
+func runDump(e *env, fs *flag.FlagSet) int {
+	if fs.NArg() != 0 {
+		return usageError(fs, "dump doesn't take any arguments")
+	}
+
+	entries, err := parse(e)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Unable to read the logbook: %v\n", err)
+		return 1
+	}
+
+	switch dumpFormat {
+	case "json":
+		// Maps are marshaled with their keys sorted, so by date.
+		byDate := map[string]*parser.LogEntry{}
+		for d, entry := range entries {
+			byDate[d.ToYmd()] = entry
+		}
+		var b []byte
+		b, err = json.MarshalIndent(byDate, "", "  ")
+		if err == nil {
+			_, err = fmt.Printf("%s\n", b)
+		}
+	case "jsonl":
+		var dates []parser.Date
+		for d := range entries {
+			dates = append(dates, d)
+		}
+		sort.Slice(dates, func(i, j int) bool {
+			return dates[i].Before(dates[j])
+		})
+
+		// Encode writes each entry followed by a newline.
+		enc := json.NewEncoder(os.Stdout)
+		for _, d := range dates {
+			if err = enc.Encode(entries[d]); err != nil {
+				break
+			}
+		}
+	default:
+		return usageError(fs, "Unknown --format %q", dumpFormat)
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Unable to dump the logbook: %v\n", err)
+		return 1
+	}
+	return 0
+}
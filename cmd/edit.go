@@ -0,0 +1,125 @@
+/**
+ * Copyright 2018 Google LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the license.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specified language governing permissions and
+ * limitations under the License.
+ */
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/achew22/logbook/config"
+	"github.com/achew22/logbook/parser"
+)
+
+var editCommand = &command{
+	name:    "edit",
+	args:    "[date]",
+	summary: "Opens the entry for a date, and the one before it, in an editor.",
+	help: `Opens the entry for a date, today by default, in an editor alongside the
+entry before it, generating it first if it doesn't exist yet. The editor is
+"editor" from the config file, $VISUAL or $EDITOR, in that order. vim opens
+the entries in split windows without line numbers, unless "editor_args" in the
+config file says otherwise.`,
+	run: runEdit,
+}
+
+// splittingEditors are the editors that "-o" opens files side by side in. Like
+// the lb script did, they also hide line numbers and start in the previous
+// entry.
+var splittingEditors = map[string]bool{
+	"vi":   true,
+	"vim":  true,
+	"nvim": true,
+	"gvim": true,
+	"mvim": true,
+}
+
+func runEdit(e *env, fs *flag.FlagSet) int {
+	if fs.NArg() > 1 {
+		return usageError(fs, "edit takes at most one date")
+	}
+
+	today := e.today
+	if fs.NArg() == 1 {
+		var err error
+		today, err = parseDate(e, fs.Arg(0))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid date provided. %s\n", err)
+			return 1
+		}
+	}
+
+	todayPath := e.parser.PathFor(today)
+	if _, err := os.Stat(todayPath); os.IsNotExist(err) {
+		if err := generate(e, today); err != nil {
+			fmt.Fprintf(os.Stderr, "%v\n", err)
+			return 1
+		}
+	}
+
+	editor := editorCommand(e.config)
+	if len(editor) == 0 {
+		fmt.Fprintf(os.Stderr, "No editor is configured. Set $EDITOR or \"editor\" in the config file.\n")
+		return 1
+	}
+
+	// Warnings would only be hidden by the editor, so they're left to the
+	// other commands.
+	entries, err := e.parser.Parse()
+	if _, ok := err.(parser.Warnings); !ok && err != nil {
+		fmt.Fprintf(os.Stderr, "Unable to read the logbook: %v\n", err)
+		return 1
+	}
+
+	args := append(editor[1:], editorArgs(e.config, editor[0])...)
+	if prev := parser.PreviousEntry(entries, today); prev != nil {
+		args = append(args, prev.Path)
+	}
+	args = append(args, todayPath)
+
+	cmd := exec.Command(editor[0], args...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		fmt.Fprintf(os.Stderr, "Unable to run %s: %v\n", editor[0], err)
+		return 1
+	}
+	return 0
+}
+
+// editorCommand returns the editor and any arguments it was configured with.
+func editorCommand(c *config.Config) []string {
+	for _, editor := range []string{c.Editor, os.Getenv("VISUAL"), os.Getenv("EDITOR")} {
+		if fields := strings.Fields(editor); len(fields) > 0 {
+			return fields
+		}
+	}
+	return nil
+}
+
+func editorArgs(c *config.Config, editor string) []string {
+	if c.EditorArgs != nil {
+		return c.EditorArgs
+	}
+	if splittingEditors[filepath.Base(editor)] {
+		return []string{"+windo set nonumber", "+wincmd k", "-o"}
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+/**
+ * Copyright 2018 Google LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the license.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specified language governing permissions and
+ * limitations under the License.
+ */
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/achew22/logbook/ics"
+	"github.com/achew22/logbook/parser"
+)
+
+var exportOutput string
+
+var exportCommand = &command{
+	name:    "export",
+	args:    "ics",
+	summary: "Exports the reminders to a calendar file.",
+	help: `Exports every reminder as an all day event in an iCalendar (.ics) file, which
+calendar apps can subscribe to. Events keep the same UID from one export to the
+next so that calendars update them instead of adding duplicates.`,
+	setFlags: func(fs *flag.FlagSet) {
+		fs.StringVar(&exportOutput, "output", "", "The file to write the calendar to. Defaults to stdout.")
+	},
+	run: runExport,
+}
+
+func runExport(e *env, fs *flag.FlagSet) int {
+	if fs.NArg() != 1 {
+		return usageError(fs, "export takes exactly one format")
+	}
+	if fs.Arg(0) != "ics" {
+		return usageError(fs, "Unknown format %q", fs.Arg(0))
+	}
+
+	entries, err := parse(e)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Unable to read the logbook: %v\n", err)
+		return 1
+	}
+
+	events := reminderEvents(e, entries)
+	if exportOutput == "" {
+		err = ics.Write(os.Stdout, events)
+	} else {
+		var f *os.File
+		f, err = os.Create(exportOutput)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Unable to create %s: %v\n", exportOutput, err)
+			return 1
+		}
+		err = ics.Write(f, events)
+		// Writes can fail as late as closing the file, which leaves the
+		// calendar cut short.
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Unable to write the calendar: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+func sortedDates(dates map[parser.Date][]*parser.Reminder) []parser.Date {
+	var out []parser.Date
+	for d := range dates {
+		out = append(out, d)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Before(out[j])
+	})
+	return out
+}
+
+// reminderEvents turns every reminder written in the logbook into an event on
+// the day it is for, ordered by that day and then by when it was written.
+func reminderEvents(e *env, entries map[parser.Date]*parser.LogEntry) []*ics.Event {
+	var targets []parser.Date
+	for d, entry := range entries {
+		if len(entry.PastReferences) > 0 {
+			targets = append(targets, d)
+		}
+	}
+	sort.Slice(targets, func(i, j int) bool {
+		return targets[i].Before(targets[j])
+	})
+
+	var events []*ics.Event
+	seen := map[string]bool{}
+	for _, target := range targets {
+		refs := entries[target].PastReferences
+		for _, origin := range sortedDates(refs) {
+			for _, reminder := range refs[origin] {
+				// Events imported from calendars are already in one, and
+				// markdown the parser skipped was never a reminder.
+				if reminder.Unknown() || filepath.Ext(reminder.Path) == parser.CalendarExt {
+					continue
+				}
+				// The UID doesn't include the line, so that the event stays
+				// the same as lines are added above it.
+				uid := ics.UID(origin.ToYmd(), target.ToYmd(), reminder.Text)
+				// The same reminder written twice is the same event.
+				if seen[uid] {
+					continue
+				}
+				seen[uid] = true
+
+				where := reminder.Path
+				if reminder.Line > 0 {
+					where = fmt.Sprintf("%s:%d", where, reminder.Line)
+				}
+				events = append(events, &ics.Event{
+					UID:         uid,
+					Summary:     reminder.Text,
+					Description: fmt.Sprintf("Written on %s in %s", origin.ToYmd(), where),
+					Start:       target.ToTime(),
+					AllDay:      true,
+					// The time the reminder was written is as close to when
+					// it was created as we know, and doesn't change.
+					Stamp: origin.ToTime(),
+				})
+			}
+		}
+	}
+	return events
+}
@@ -0,0 +1,84 @@
+/**
+ * Copyright 2018 Google LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the license.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specified language governing permissions and
+ * limitations under the License.
+ */
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/achew22/logbook/ics"
+	"github.com/achew22/logbook/parser"
+)
+
+// calendarDir is where imported calendars are kept in the logbook.
+const calendarDir = "calendars"
+
+var importCommand = &command{
+	name:    "import",
+	args:    "ics <file>",
+	summary: "Imports the events in a calendar file as reminders.",
+	help: `Imports the events in an iCalendar (.ics) file as reminders on the day they
+happen. The file is copied into the logbook, replacing any calendar imported
+before with the same name, so importing an updated export of a calendar
+replaces its events.`,
+	run: runImport,
+}
+
+func runImport(e *env, fs *flag.FlagSet) int {
+	if fs.NArg() != 2 {
+		return usageError(fs, "import takes a format and a file")
+	}
+	if fs.Arg(0) != "ics" {
+		return usageError(fs, "Unknown format %q", fs.Arg(0))
+	}
+
+	src := fs.Arg(1)
+	b, err := ioutil.ReadFile(src)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Unable to read %s: %v\n", src, err)
+		return 1
+	}
+
+	events, err := ics.Read(bytes.NewReader(b))
+	if warnings, ok := err.(ics.Warnings); ok {
+		for _, w := range warnings {
+			fmt.Fprintf(os.Stderr, "Warning: %s: %s\n", src, w)
+		}
+	} else if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid calendar %s: %v\n", src, err)
+		return 1
+	}
+
+	name := filepath.Base(src)
+	name = strings.TrimSuffix(name, filepath.Ext(name)) + parser.CalendarExt
+	dst := filepath.Join(e.config.LogPath, calendarDir, name)
+	if err := os.MkdirAll(filepath.Dir(dst), 0777); err != nil {
+		fmt.Fprintf(os.Stderr, "Unable to create the directory for %s: %v\n", dst, err)
+		return 1
+	}
+	if err := ioutil.WriteFile(dst, b, 0666); err != nil {
+		fmt.Fprintf(os.Stderr, "Unable to write %s: %v\n", dst, err)
+		return 1
+	}
+
+	fmt.Fprintf(os.Stderr, "Imported %d events to %q\n", len(events), dst)
+	return 0
+}
@@ -0,0 +1,225 @@
+/**
+ * Copyright 2018 Google LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the license.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specified language governing permissions and
+ * limitations under the License.
+ */
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/achew22/logbook/parser"
+)
+
+var (
+	lintFormat    string
+	lintStdinPath string
+)
+
+var lintCommand = &command{
+	name:    "lint",
+	args:    "[files...]",
+	summary: "Prints every parse error in the logbook.",
+	help: `Prints every parse error in the logbook as "file:line:column: message", oldest
+first, after a warning for each file that couldn't be parsed at all. Paths are
+relative to the logbook. Exits with 1 if there are any parse errors.
+
+Given files, only those are checked, and they're printed as they were named.
+A file named "-" is read from stdin, as if it were saved at --stdin_path, so
+that editors can check an entry before saving it.
+
+With --format=json the problems are printed as a JSON array instead, and with
+--format=sarif as a SARIF log for code scanning tools.`,
+	setFlags: func(fs *flag.FlagSet) {
+		fs.StringVar(&lintFormat, "format", "text", "The format to print, either \"text\", \"json\" or \"sarif\".")
+		fs.StringVar(&lintStdinPath, "stdin_path", "", "Where the file read from stdin would be saved. Defaults to today's entry.")
+	},
+	run: runLint,
+}
+
+// diagnostic is a problem lint found.
+type diagnostic struct {
+	Path     string `json:"path"`
+	Line     int    `json:"line,omitempty"`
+	Column   int    `json:"column,omitempty"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+}
+
+const (
+	severityError   = "error"
+	severityWarning = "warning"
+)
+
+func runLint(e *env, fs *flag.FlagSet) int {
+	switch lintFormat {
+	case "text", "json", "sarif":
+	default:
+		return usageError(fs, "Unknown --format %q", lintFormat)
+	}
+
+	var diagnostics []*diagnostic
+	var err error
+	if fs.NArg() == 0 {
+		diagnostics, err = lintLogbook(e)
+	} else {
+		diagnostics, err = lintFiles(e, fs.Args())
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		return 1
+	}
+
+	switch lintFormat {
+	case "text":
+		for _, d := range diagnostics {
+			switch {
+			case d.Severity == severityWarning:
+				fmt.Printf("%s: warning: %s\n", d.Path, d.Message)
+			case d.Line > 0:
+				fmt.Printf("%s:%d:%d: %s\n", d.Path, d.Line, d.Column, d.Message)
+			default:
+				fmt.Printf("%s: %s\n", d.Path, d.Message)
+			}
+		}
+	case "json":
+		if diagnostics == nil {
+			// An empty list rather than null.
+			diagnostics = []*diagnostic{}
+		}
+		err = printJSON(diagnostics)
+	case "sarif":
+		err = printJSON(sarifLog(diagnostics))
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Unable to print the problems: %v\n", err)
+		return 1
+	}
+
+	for _, d := range diagnostics {
+		if d.Severity == severityError {
+			return 1
+		}
+	}
+	return 0
+}
+
+func printJSON(v interface{}) error {
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Printf("%s\n", b)
+	return err
+}
+
+// lintLogbook returns the problems in every file in the logbook.
+func lintLogbook(e *env) ([]*diagnostic, error) {
+	entries, err := e.parser.Parse()
+	warnings, _ := err.(parser.Warnings)
+	if err != nil && warnings == nil {
+		return nil, fmt.Errorf("Unable to read the logbook: %v", err)
+	}
+
+	var diagnostics []*diagnostic
+	for _, w := range warnings {
+		diagnostics = append(diagnostics, &diagnostic{
+			Path:     relPath(e, w.Path),
+			Severity: severityWarning,
+			Message:  w.Message,
+		})
+	}
+
+	var dates []parser.Date
+	for d, entry := range entries {
+		if len(entry.Errors) > 0 {
+			dates = append(dates, d)
+		}
+	}
+	sort.Slice(dates, func(i, j int) bool {
+		return dates[i].Before(dates[j])
+	})
+	for _, d := range dates {
+		entry := entries[d]
+		for _, err := range entry.Errors {
+			path := err.Path
+			if path == "" {
+				path = entry.Path
+			}
+			diagnostics = append(diagnostics, &diagnostic{
+				Path:     relPath(e, path),
+				Line:     err.Line,
+				Column:   err.Column,
+				Severity: severityError,
+				Message:  err.Message,
+			})
+		}
+	}
+	return diagnostics, nil
+}
+
+// lintFiles returns the problems in each of names, in order.
+func lintFiles(e *env, names []string) ([]*diagnostic, error) {
+	var diagnostics []*diagnostic
+	for _, name := range names {
+		var b []byte
+		var err error
+		path := name
+		if name == "-" {
+			b, err = ioutil.ReadAll(os.Stdin)
+			path = lintStdinPath
+			if path == "" {
+				path = e.parser.PathFor(e.today)
+			} else {
+				name = path
+			}
+		} else {
+			b, err = ioutil.ReadFile(name)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("Unable to read %s: %v", name, err)
+		}
+
+		// The parser works out dates from paths in the logbook.
+		path, err = filepath.Abs(path)
+		if err != nil {
+			return nil, err
+		}
+		errs, err := e.parser.Lint(path, b)
+		if w, ok := err.(*parser.Warning); ok {
+			diagnostics = append(diagnostics, &diagnostic{
+				Path:     name,
+				Severity: severityWarning,
+				Message:  w.Message,
+			})
+		} else if err != nil {
+			return nil, err
+		}
+		for _, err := range errs {
+			diagnostics = append(diagnostics, &diagnostic{
+				Path:     name,
+				Line:     err.Line,
+				Column:   err.Column,
+				Severity: severityError,
+				Message:  err.Message,
+			})
+		}
+	}
+	return diagnostics, nil
+}
@@ -0,0 +1,88 @@
+/**
+ * Copyright 2018 Google LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the license.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specified language governing permissions and
+ * limitations under the License.
+ */
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/achew22/logbook/parser"
+)
+
+var (
+	listSince string
+	listUntil string
+	listPaths bool
+)
+
+var listCommand = &command{
+	name:    "list",
+	summary: "Lists the dates of every entry.",
+	help:    "Lists the dates of every entry, oldest first.",
+	setFlags: func(fs *flag.FlagSet) {
+		fs.StringVar(&listSince, "since", "", "Only lists entries on or after this date. Example --since=2000-01-01")
+		fs.StringVar(&listUntil, "until", "", "Only lists entries on or before this date. Example --until=yesterday")
+		fs.BoolVar(&listPaths, "paths", false, "Prints the path of each entry instead of its date.")
+	},
+	run: runList,
+}
+
+func runList(e *env, fs *flag.FlagSet) int {
+	if fs.NArg() != 0 {
+		return usageError(fs, "list doesn't take any arguments")
+	}
+
+	var since, until parser.Date
+	if listSince != "" {
+		var err error
+		since, err = parseDate(e, listSince)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid --since provided. %s\n", err)
+			return 1
+		}
+	}
+	if listUntil != "" {
+		var err error
+		until, err = parseDate(e, listUntil)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid --until provided. %s\n", err)
+			return 1
+		}
+	}
+
+	entries, err := parse(e)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Unable to read the logbook: %v\n", err)
+		return 1
+	}
+
+	for _, entry := range entriesOnDisk(entries) {
+		if listSince != "" && entry.Date.Before(since) {
+			continue
+		}
+		if listUntil != "" && entry.Date.After(until) {
+			continue
+		}
+
+		if listPaths {
+			fmt.Println(entry.Path)
+		} else {
+			fmt.Println(entry.Date.ToYmd())
+		}
+	}
+	return 0
+}
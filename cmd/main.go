@@ -19,81 +19,99 @@ import (
 	"flag"
 	"fmt"
 	"os"
-	"path/filepath"
 	"time"
 
 	"github.com/achew22/logbook/config"
 	"github.com/achew22/logbook/parser"
-	"github.com/achew22/logbook/templater"
 )
 
 var (
-	nameOverride = flag.String("name_override", "", "Overrides the name of the user in the heading. Example --name_override=\"Joe Armstrong\"")
-	dateOverride = flag.String("date_override", "", "Overrides the current date taking the form \"yyyy-mm-dd\". Example --date_override=1941-12-07")
+	nameOverride       = flag.String("name_override", "", "Overrides the name of the user in the heading. Example --name_override=\"Joe Armstrong\"")
+	dateOverride       = flag.String("date_override", "", "Overrides the current date taking the form \"yyyy-mm-dd\". Example --date_override=1941-12-07")
+	skipNonWorkingDays = flag.Bool("skip_non_working_days", false, "Moves reminders that fall on a weekend to the next working day.")
+	newestFirst        = flag.Bool("newest_first", false, "Lists reminders and parse errors from newest to oldest.")
+	perfRollupDays     = flag.Int("perf_rollup_days", 14, "How many days before the end of a quarter to add the quarter's perf notes to the log entry. Negative values disable the roll-up.")
+	noCache            = flag.Bool("no_cache", false, "Parses every entry instead of using the cache of entries that haven't changed.")
 )
 
+// env is what every command is run with.
+type env struct {
+	config *config.Config
+	parser *parser.Parser
+	today  parser.Date
+
+	// cachePath is where parsed entries are cached, or empty if they aren't.
+	cachePath string
+}
+
 func main() {
+	flag.Usage = usage
 	flag.Parse()
 
-	c := &config.Config{
-		Name:    *nameOverride,
-		LogPath: os.ExpandEnv("${HOME}/logbook"),
+	c, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid config file: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Flags that were explicitly set take precedence over the config file.
+	flag.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "name_override":
+			if *nameOverride != "" {
+				c.Name = *nameOverride
+			}
+		case "skip_non_working_days":
+			c.SkipNonWorkingDays = *skipNonWorkingDays
+		case "perf_rollup_days":
+			c.PerfRollupDays = *perfRollupDays
+		case "newest_first":
+			c.NewestFirst = *newestFirst
+		}
+	})
+
+	e := &env{
+		config: c,
+		parser: parser.New(c),
 	}
-	p := parser.New(c)
 
-	if *nameOverride == "" {
-		c.Name = "Andrew Allen"
+	if !*noCache {
+		// Without a cache directory, everything is parsed every time.
+		if path, err := parser.DefaultCachePath(c); err == nil {
+			e.cachePath = path
+			e.parser.UseCache(path)
+		}
 	}
 
-	var today parser.Date
 	if *dateOverride == "" {
-		today = parser.TimeToDate(time.Now())
+		e.today = parser.TimeToDate(time.Now())
 	} else {
 		var err error
-		today, err = parser.YmdToDate(*dateOverride)
+		e.today, err = parser.YmdToDate(*dateOverride)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Invalid --date_override provided. %s", err)
 			os.Exit(1)
 		}
 	}
-	fmt.Fprintf(os.Stderr, "Writing log entry for %s\n", today.ToYmd())
-
-	if _, err := os.Stat(c.LogPath); err != nil {
-		fmt.Fprintf(os.Stderr, "Creating %s\n", c.LogPath)
-		err := os.MkdirAll(c.LogPath, 0777)
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Log path %s does not exist and could not be created\n", c.LogPath)
-			os.Exit(1)
-		}
-	}
-
-	todayPath := filepath.Join(c.LogPath, today.ToYmd()+".md")
-
-	if _, err := os.Stat(todayPath); err == nil {
-		fmt.Fprintf(os.Stderr, "A file already exists by the name %s\n", todayPath)
-		os.Exit(1)
-	}
 
-	out, err := os.Create(todayPath)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Unable to create a log entry named %s.\nErr: %v", todayPath, err)
-		os.Exit(1)
+	// With no command, generate today's entry.
+	name := flag.Arg(0)
+	if name == "" {
+		name = "new"
 	}
 
-	parsedOutput := p.Parse()
-	text := templater.Print(c, parsedOutput, today)
-
-	if _, err := out.Write([]byte(text)); err != nil {
-		fmt.Fprintf(os.Stderr, "Error writing file: %v", err)
+	cmd := lookupCommand(name)
+	if cmd == nil {
+		fmt.Fprintf(os.Stderr, "Unknown command %q\n", name)
 		os.Exit(1)
 	}
 
-	if err := out.Close(); err != nil {
-		fmt.Fprintf(os.Stderr, "Error closing file: %v", err)
-		os.Exit(1)
+	var args []string
+	if flag.NArg() > 1 {
+		args = flag.Args()[1:]
 	}
+	fs := cmd.flagSet()
+	fs.Parse(args)
 
-	fmt.Fprintf(os.Stderr, "Wrote file %q\n", out.Name())
-
-	os.Exit(0)
+	os.Exit(cmd.run(e, fs))
 }
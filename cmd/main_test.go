@@ -1,6 +1,7 @@
 package main
 
 import (
+	"bytes"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -13,6 +14,8 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 
+	"github.com/achew22/logbook/config"
+	"github.com/achew22/logbook/ics"
 	"github.com/achew22/logbook/parser"
 )
 
@@ -187,3 +190,716 @@ func TestCreateLogDirectory(t *testing.T) {
 		t.Errorf("Inequal stderr/out:\nwant: %q\ngot:  %q", want, gotString)
 	}
 }
+
+func TestActions(t *testing.T) {
+	dir, cleanup := makeFakeHome(t)
+	defer cleanup()
+	t.Logf("Dir: %s", dir)
+
+	makeLogEntry(t, dir, "2000-01-01", `# Andrew Allen - 2000-01-01
+
+AI(bob): Send the design doc
+
+AI(me): Review bob's design doc
+`)
+	makeLogEntry(t, dir, "2000-01-02", `# Andrew Allen - 2000-01-02
+
+ *  [x] AI(me): Review bob's design doc
+ *  [ ] AI(bob): Book a room for the review
+`)
+
+	got, err := helperCommand(t, dir, "actions").CombinedOutput()
+	if err != nil {
+		t.Errorf("Invocation failed: %v\ngot:  %q", err, got)
+	}
+
+	want := `bob:
+
+ *  2000-01-01: Send the design doc
+ *  2000-01-02: Book a room for the review`
+	if gotString := trim(string(got)); gotString != want {
+		t.Errorf("Inequal stderr/out:\nwant: %q\ngot:  %q", want, gotString)
+	}
+}
+
+func TestUnknownCommand(t *testing.T) {
+	dir, cleanup := makeFakeHome(t)
+	defer cleanup()
+
+	got, err := helperCommand(t, dir, "frobnicate").CombinedOutput()
+	if err == nil {
+		t.Errorf("Invocation succeeded when it shouldn't have: %q", got)
+	}
+	want := `Unknown command "frobnicate"`
+	if gotString := trim(string(got)); gotString != want {
+		t.Errorf("Inequal stderr/out:\nwant: %q\ngot:  %q", want, gotString)
+	}
+}
+
+func TestPerf(t *testing.T) {
+	dir, cleanup := makeFakeHome(t)
+	defer cleanup()
+	t.Logf("Dir: %s", dir)
+
+	makeLogEntry(t, dir, "2000-06-30", `# Andrew Allen - 2000-06-30
+
+perf: Closed out the quarter's OKRs
+`)
+	makeLogEntry(t, dir, "2000-07-03", `# Andrew Allen - 2000-07-03
+
+perf: Launched the new dashboard
+`)
+
+	got, err := helperCommand(t, dir, "perf", "--quarter=2000Q3").CombinedOutput()
+	if err != nil {
+		t.Errorf("Invocation failed: %v\ngot:  %q", err, got)
+	}
+
+	want := `## Perf notes for 2000Q3
+
+ *  2000-07-03: Launched the new dashboard`
+	if gotString := trim(string(got)); gotString != want {
+		t.Errorf("Inequal stderr/out:\nwant: %q\ngot:  %q", want, gotString)
+	}
+}
+
+func TestConfigFile(t *testing.T) {
+	dir, cleanup := makeFakeHome(t)
+	defer cleanup()
+	t.Logf("Dir: %s", dir)
+
+	configDir := filepath.Join(dir, ".config", "logbook")
+	if err := os.MkdirAll(configDir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	err := ioutil.WriteFile(filepath.Join(configDir, "config.toml"), []byte(`
+name = "Joe Armstrong"
+log_path = "${HOME}/notes"
+file_format = "2006/01-02.md"
+`), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := helperCommand(t, dir, "--date_override=2000-01-01").CombinedOutput()
+	if err != nil {
+		t.Errorf("Invocation failed: %v\ngot:  %q", err, got)
+	}
+	want := fmt.Sprintf("Writing log entry for 2000-01-01\nCreating %s/notes\nWrote file \"%s/notes/2000/01-01.md\"", dir, dir)
+	if gotString := trim(string(got)); gotString != want {
+		t.Errorf("Inequal stderr/out:\nwant: %q\ngot:  %q", want, gotString)
+	}
+
+	contents, err := ioutil.ReadFile(filepath.Join(dir, "notes", "2000", "01-01.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "# Joe Armstrong - 2000-01-01\n"; !strings.HasPrefix(string(contents), want) {
+		t.Errorf("Expected the entry to start with %q, got %q", want, contents)
+	}
+
+	// Flags override the config file.
+	if out, err := helperCommand(t, dir, "--date_override=2000-01-02", "--name_override=Ada Lovelace").CombinedOutput(); err != nil {
+		t.Errorf("Invocation failed: %v\ngot:  %q", err, out)
+	}
+	contents, err = ioutil.ReadFile(filepath.Join(dir, "notes", "2000", "01-02.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "# Ada Lovelace - 2000-01-02\n"; !strings.HasPrefix(string(contents), want) {
+		t.Errorf("Expected the entry to start with %q, got %q", want, contents)
+	}
+}
+
+func TestInvalidConfigFile(t *testing.T) {
+	dir, cleanup := makeFakeHome(t)
+	defer cleanup()
+
+	configDir := filepath.Join(dir, ".config", "logbook")
+	if err := os.MkdirAll(configDir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	configPath := filepath.Join(configDir, "config.toml")
+	if err := ioutil.WriteFile(configPath, []byte(`working_days = ["caturday"]`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := helperCommand(t, dir).CombinedOutput()
+	if err == nil {
+		t.Errorf("Invocation succeeded when it shouldn't have: %q", got)
+	}
+	want := fmt.Sprintf("Invalid config file: %s: working_days: unknown day of the week \"caturday\"", configPath)
+	if gotString := trim(string(got)); gotString != want {
+		t.Errorf("Inequal stderr/out:\nwant: %q\ngot:  %q", want, gotString)
+	}
+}
+
+func TestNewWithDate(t *testing.T) {
+	dir, cleanup := makeFakeHome(t)
+	defer cleanup()
+	t.Logf("Dir: %s", dir)
+
+	got, err := helperCommand(t, dir, "--date_override=2000-01-03", "new", "tomorrow").CombinedOutput()
+	if err != nil {
+		t.Errorf("Invocation failed: %v\ngot:  %q", err, got)
+	}
+	want := fmt.Sprintf("Writing log entry for 2000-01-04\nCreating %s/logbook\nWrote file \"%s/logbook/2000-01-04.md\"", dir, dir)
+	if gotString := trim(string(got)); gotString != want {
+		t.Errorf("Inequal stderr/out:\nwant: %q\ngot:  %q", want, gotString)
+	}
+}
+
+func TestHelp(t *testing.T) {
+	dir, cleanup := makeFakeHome(t)
+	defer cleanup()
+
+	got, err := helperCommand(t, dir, "help").CombinedOutput()
+	if err != nil {
+		t.Errorf("Invocation failed: %v\ngot:  %q", err, got)
+	}
+	for _, c := range commands {
+		if !strings.Contains(string(got), "\n  "+c.name+" ") {
+			t.Errorf("Expected %q to be in the list of commands:\n%s", c.name, got)
+		}
+	}
+
+	got, err = helperCommand(t, dir, "help", "list").CombinedOutput()
+	if err != nil {
+		t.Errorf("Invocation failed: %v\ngot:  %q", err, got)
+	}
+	if want := "Usage: logbook list [flags]\n\nLists the dates of every entry, oldest first.\n\nFlags:\n"; !strings.HasPrefix(string(got), want) {
+		t.Errorf("Expected the help to start with %q, got %q", want, got)
+	}
+}
+
+func TestEdit(t *testing.T) {
+	dir, cleanup := makeFakeHome(t)
+	defer cleanup()
+	t.Logf("Dir: %s", dir)
+
+	// Entries that don't sort lexicographically, which the previous entry
+	// is found despite.
+	makeLogEntry(t, dir, "2000-1-9", "# Andrew Allen - 2000-01-09\n")
+	makeLogEntry(t, dir, "2000-01-10", "# Andrew Allen - 2000-01-10\n")
+
+	cmd := helperCommand(t, dir, "--date_override=2000-01-12", "edit")
+	cmd.Env = append(cmd.Env, "PATH="+os.Getenv("PATH"), "EDITOR=echo")
+	got, err := cmd.Output()
+	if err != nil {
+		t.Errorf("Invocation failed: %v\ngot:  %q", err, got)
+	}
+	want := fmt.Sprintf("%s/logbook/2000-01-10.md %s/logbook/2000-01-12.md", dir, dir)
+	if gotString := trim(string(got)); gotString != want {
+		t.Errorf("Inequal stdout:\nwant: %q\ngot:  %q", want, gotString)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "logbook", "2000-01-12.md")); err != nil {
+		t.Errorf("Expected the entry to be generated: %v", err)
+	}
+
+	// The editor from the config file takes precedence, and the entry isn't
+	// generated again.
+	configDir := filepath.Join(dir, ".config", "logbook")
+	if err := os.MkdirAll(configDir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	err = ioutil.WriteFile(filepath.Join(configDir, "config.toml"), []byte(`
+editor = "echo vim"
+editor_args = ["+wincmd k", "-o"]
+`), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cmd = helperCommand(t, dir, "--date_override=2000-01-12", "edit")
+	cmd.Env = append(cmd.Env, "PATH="+os.Getenv("PATH"), "EDITOR=false")
+	got, err = cmd.CombinedOutput()
+	if err != nil {
+		t.Errorf("Invocation failed: %v\ngot:  %q", err, got)
+	}
+	want = fmt.Sprintf("vim +wincmd k -o %s/logbook/2000-01-10.md %s/logbook/2000-01-12.md", dir, dir)
+	if gotString := trim(string(got)); gotString != want {
+		t.Errorf("Inequal stderr/out:\nwant: %q\ngot:  %q", want, gotString)
+	}
+
+	// The previous entry is opened even if it was named by hand.
+	cmd = helperCommand(t, dir, "--date_override=2000-01-10", "edit")
+	cmd.Env = append(cmd.Env, "PATH="+os.Getenv("PATH"))
+	got, err = cmd.CombinedOutput()
+	if err != nil {
+		t.Errorf("Invocation failed: %v\ngot:  %q", err, got)
+	}
+	want = fmt.Sprintf("vim +wincmd k -o %s/logbook/2000-1-9.md %s/logbook/2000-01-10.md", dir, dir)
+	if gotString := trim(string(got)); gotString != want {
+		t.Errorf("Inequal stderr/out:\nwant: %q\ngot:  %q", want, gotString)
+	}
+}
+
+func TestEditorArgs(t *testing.T) {
+	vim := []string{"+windo set nonumber", "+wincmd k", "-o"}
+	for _, test := range []struct {
+		editor string
+		args   []string
+		want   []string
+	}{
+		{"vim", nil, vim},
+		{"/usr/bin/nvim", nil, vim},
+		{"vim", []string{}, []string{}},
+		{"vim", []string{"-O"}, []string{"-O"}},
+		{"emacs", nil, nil},
+	} {
+		got := editorArgs(&config.Config{EditorArgs: test.args}, test.editor)
+		if diff := cmp.Diff(got, test.want); diff != "" {
+			t.Errorf("editorArgs(%q, %q) differences:\n%s", test.editor, test.args, diff)
+		}
+	}
+}
+
+func TestRefresh(t *testing.T) {
+	dir, cleanup := makeFakeHome(t)
+	defer cleanup()
+	t.Logf("Dir: %s", dir)
+
+	makeLogEntry(t, dir, "2000-01-01", `# Andrew Allen - 2000-01-01
+
+tomorrow: Water the plants
+`)
+	makeLogEntry(t, dir, "2000-01-02", `# Andrew Allen - 2000-01-02
+
+<!-- logbook:begin -->
+
+## Reminders:
+
+From 2000-01-01:
+
+ *  Water the plants
+
+<!-- logbook:end -->
+
+Remembered something else.
+
+in 0 days: Call home
+`)
+
+	if out, err := helperCommand(t, dir, "--date_override=2000-01-02").CombinedOutput(); err == nil {
+		t.Errorf("Expected generating an existing entry to fail, got %q", out)
+	}
+
+	got, err := helperCommand(t, dir, "--date_override=2000-01-02", "new", "--refresh").CombinedOutput()
+	if err != nil {
+		t.Errorf("Invocation failed: %v\ngot:  %q", err, got)
+	}
+	want := fmt.Sprintf("Refreshing log entry for 2000-01-02\nWrote file \"%s/logbook/2000-01-02.md\"", dir)
+	if gotString := trim(string(got)); gotString != want {
+		t.Errorf("Inequal stderr/out:\nwant: %q\ngot:  %q", want, gotString)
+	}
+
+	assertLogEntry(t, dir, "2000-01-02", `# Andrew Allen - 2000-01-02
+
+<!-- logbook:begin -->
+
+## Reminders:
+
+From 2000-01-01:
+
+ *  Water the plants (2000-01-01.md:3)
+
+From 2000-01-02:
+
+ *  Call home (2000-01-02.md:15)
+
+<!-- logbook:end -->
+
+Remembered something else.
+
+in 0 days: Call home
+`)
+}
+
+func TestRefreshKeepsChecked(t *testing.T) {
+	dir, cleanup := makeFakeHome(t)
+	defer cleanup()
+	t.Logf("Dir: %s", dir)
+
+	makeLogEntry(t, dir, "2000-01-03", `# Andrew Allen - 2000-01-03
+
+ *  [ ] Book the room
+
+TODO: Write the report
+`)
+	if out, err := helperCommand(t, dir, "--date_override=2000-01-04").CombinedOutput(); err != nil {
+		t.Fatalf("Invocation failed: %v\n%s", err, out)
+	}
+
+	path := filepath.Join(dir, "logbook", "2000-01-04.md")
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Count(string(b), "[ ]") != 2 {
+		t.Fatalf("Expected two unchecked items:\n%s", b)
+	}
+	ticked := strings.Replace(string(b), "[ ]", "[x]", -1)
+	if err := ioutil.WriteFile(path, []byte(ticked), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if out, err := helperCommand(t, dir, "--date_override=2000-01-04", "new", "--refresh").CombinedOutput(); err != nil {
+		t.Fatalf("Invocation failed: %v\n%s", err, out)
+	}
+	assertLogEntry(t, dir, "2000-01-04", ticked)
+}
+
+func TestForce(t *testing.T) {
+	dir, cleanup := makeFakeHome(t)
+	defer cleanup()
+	t.Logf("Dir: %s", dir)
+
+	original := "# Andrew Allen - 2000-01-02\n\nWritten by hand.\n"
+	makeLogEntry(t, dir, "2000-01-02", original)
+
+	got, err := helperCommand(t, dir, "--date_override=2000-01-02", "new", "--force").CombinedOutput()
+	if err != nil {
+		t.Errorf("Invocation failed: %v\ngot:  %q", err, got)
+	}
+	path := filepath.Join(dir, "logbook", "2000-01-02.md")
+	want := fmt.Sprintf("Backed up %s to %s.bak\nWriting log entry for 2000-01-02\nWrote file %q", path, path, path)
+	if gotString := trim(string(got)); gotString != want {
+		t.Errorf("Inequal stderr/out:\nwant: %q\ngot:  %q", want, gotString)
+	}
+
+	assertLogEntry(t, dir, "2000-01-02", `# Andrew Allen - 2000-01-02
+
+<!-- logbook:begin -->
+
+There are no reminders for today
+
+<!-- logbook:end -->
+
+`)
+	backup, err := ioutil.ReadFile(path + ".bak")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(backup) != original {
+		t.Errorf("Expected the backup to be %q, got %q", original, backup)
+	}
+
+	// Forcing it again keeps the first backup.
+	if out, err := helperCommand(t, dir, "--date_override=2000-01-02", "new", "--force").CombinedOutput(); err != nil {
+		t.Errorf("Invocation failed: %v\ngot:  %q", err, out)
+	}
+	if backup, err := ioutil.ReadFile(path + ".bak"); err != nil || string(backup) != original {
+		t.Errorf("Expected the first backup to be kept, got %q, %v", backup, err)
+	}
+	if _, err := os.Stat(path + ".bak.1"); err != nil {
+		t.Errorf("Expected a second backup: %v", err)
+	}
+}
+
+func TestExportICS(t *testing.T) {
+	dir, cleanup := makeFakeHome(t)
+	defer cleanup()
+	t.Logf("Dir: %s", dir)
+
+	makeLogEntry(t, dir, "2000-01-01", `# Andrew Allen - 2000-01-01
+
+2000-01-03: Pick up the cake, candles
+
+tomorrow: Water the plants
+
+tomorrow: Water the plants
+
+<div>Not a reminder</div>
+`)
+	// Events from calendars in the logbook aren't exported again.
+	calendars := filepath.Join(dir, "logbook", "calendars")
+	if err := os.MkdirAll(calendars, 0700); err != nil {
+		t.Fatal(err)
+	}
+	err := ioutil.WriteFile(filepath.Join(calendars, "work.ics"), []byte(strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"BEGIN:VEVENT",
+		"DTSTART;VALUE=DATE:20000104",
+		"SUMMARY:Team lunch",
+		"END:VEVENT",
+		"END:VCALENDAR",
+		"",
+	}, "\r\n")), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := helperCommand(t, dir, "export", "ics").Output()
+	if err != nil {
+		t.Errorf("Invocation failed: %v\ngot:  %q", err, got)
+	}
+
+	// Long lines are folded, which depends on how long the path to the
+	// logbook is.
+	gotString := strings.Replace(string(got), "\r\n ", "", -1)
+	gotString = strings.Replace(gotString, dir, "$HOME", -1)
+
+	want := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//achew22//logbook//EN",
+		"CALSCALE:GREGORIAN",
+		"BEGIN:VEVENT",
+		"UID:" + ics.UID("2000-01-01", "2000-01-02", "Water the plants"),
+		"DTSTAMP:20000101T000000Z",
+		"DTSTART;VALUE=DATE:20000102",
+		"DTEND;VALUE=DATE:20000103",
+		"SUMMARY:Water the plants",
+		"DESCRIPTION:Written on 2000-01-01 in $HOME/logbook/2000-01-01.md:5",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:" + ics.UID("2000-01-01", "2000-01-03", "Pick up the cake, candles"),
+		"DTSTAMP:20000101T000000Z",
+		"DTSTART;VALUE=DATE:20000103",
+		"DTEND;VALUE=DATE:20000104",
+		`SUMMARY:Pick up the cake\, candles`,
+		"DESCRIPTION:Written on 2000-01-01 in $HOME/logbook/2000-01-01.md:3",
+		"END:VEVENT",
+		"END:VCALENDAR",
+		"",
+	}, "\r\n")
+	if diff := cmp.Diff(strings.Split(gotString, "\r\n"), strings.Split(want, "\r\n")); diff != "" {
+		t.Errorf("Differences:\n%s", diff)
+	}
+}
+
+func TestExportICSWriteError(t *testing.T) {
+	if _, err := os.Stat("/dev/full"); err != nil {
+		t.Skip("There's no /dev/full to fail writing to")
+	}
+	dir, cleanup := makeFakeHome(t)
+	defer cleanup()
+
+	makeLogEntry(t, dir, "2000-01-01", "# Andrew Allen - 2000-01-01\n\ntomorrow: Water the plants\n")
+	got, err := helperCommand(t, dir, "export", "--output=/dev/full", "ics").CombinedOutput()
+	if err == nil || !strings.Contains(string(got), "Unable to write the calendar") {
+		t.Errorf("Expected writing the calendar to fail, got %q, %v", got, err)
+	}
+}
+
+func TestImportICS(t *testing.T) {
+	dir, cleanup := makeFakeHome(t)
+	defer cleanup()
+	t.Logf("Dir: %s", dir)
+
+	makeLogEntry(t, dir, "2000-01-03", "# Andrew Allen - 2000-01-03\n")
+
+	calendarPath := filepath.Join(dir, "Work Calendar.ics")
+	err := ioutil.WriteFile(calendarPath, []byte(strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"BEGIN:VEVENT",
+		"UID:standup@example.com",
+		"DTSTART:20000104T093000",
+		"SUMMARY:Standup",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:offsite@example.com",
+		"DTSTART;VALUE=DATE:20000104",
+		"SUMMARY:Team offsite",
+		"END:VEVENT",
+		"END:VCALENDAR",
+	}, "\r\n")), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := helperCommand(t, dir, "import", "ics", calendarPath).CombinedOutput()
+	if err != nil {
+		t.Errorf("Invocation failed: %v\ngot:  %q", err, got)
+	}
+	want := fmt.Sprintf("Imported 2 events to \"%s/logbook/calendars/Work Calendar.ics\"", dir)
+	if gotString := trim(string(got)); gotString != want {
+		t.Errorf("Inequal stderr/out:\nwant: %q\ngot:  %q", want, gotString)
+	}
+
+	if out, err := helperCommand(t, dir, "--date_override=2000-01-04").CombinedOutput(); err != nil {
+		t.Errorf("Invocation failed: %v\ngot:  %q", err, out)
+	}
+	assertLogEntry(t, dir, "2000-01-04", `# Andrew Allen - 2000-01-04
+
+<!-- logbook:begin -->
+
+## Reminders:
+
+From 2000-01-04:
+
+ *  09:30 Standup (calendars/Work Calendar.ics)
+ *  Team offsite (calendars/Work Calendar.ics)
+
+<!-- logbook:end -->
+
+`)
+}
+
+func TestImportInvalidICS(t *testing.T) {
+	dir, cleanup := makeFakeHome(t)
+	defer cleanup()
+
+	calendarPath := filepath.Join(dir, "calendar.ics")
+	if err := ioutil.WriteFile(calendarPath, []byte("BEGIN:VEVENT\nSUMMARY:Lunch\nEND:VEVENT\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := helperCommand(t, dir, "import", "ics", calendarPath).CombinedOutput()
+	if err == nil {
+		t.Errorf("Invocation succeeded when it shouldn't have: %q", got)
+	}
+	want := fmt.Sprintf("Invalid calendar %s: line 3: event \"Lunch\" doesn't have a DTSTART", calendarPath)
+	if gotString := trim(string(got)); gotString != want {
+		t.Errorf("Inequal stderr/out:\nwant: %q\ngot:  %q", want, gotString)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "logbook", "calendars", "calendar.ics")); !os.IsNotExist(err) {
+		t.Errorf("Expected the invalid calendar not to be imported: %v", err)
+	}
+}
+
+func TestCache(t *testing.T) {
+	dir, cleanup := makeFakeHome(t)
+	defer cleanup()
+	t.Logf("Dir: %s", dir)
+
+	makeLogEntry(t, dir, "2000-01-03", "# Andrew Allen - 2000-01-03\n\ntomorrow: Aaa\n")
+	cacheDir := filepath.Join(dir, ".cache", "logbook")
+
+	if out, err := helperCommand(t, dir, "--no_cache", "list").CombinedOutput(); err != nil {
+		t.Errorf("Invocation failed: %v\ngot:  %q", err, out)
+	}
+	if _, err := os.Stat(cacheDir); !os.IsNotExist(err) {
+		t.Errorf("Expected no cache with --no_cache, got %v", err)
+	}
+
+	if out, err := helperCommand(t, dir, "list").CombinedOutput(); err != nil {
+		t.Errorf("Invocation failed: %v\ngot:  %q", err, out)
+	}
+	files, err := ioutil.ReadDir(cacheDir)
+	if err != nil || len(files) != 1 {
+		t.Fatalf("Expected a cache in %s, got %v, %v", cacheDir, files, err)
+	}
+	cachePath := filepath.Join(cacheDir, files[0].Name())
+
+	// Change the entry without changing its size or modification time, so
+	// only a rebuild notices.
+	entryPath := filepath.Join(dir, "logbook", "2000-01-03.md")
+	info, err := os.Stat(entryPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	makeLogEntry(t, dir, "2000-01-03", "# Andrew Allen - 2000-01-03\n\ntomorrow: Bbb\n")
+	if err := os.Chtimes(entryPath, info.ModTime(), info.ModTime()); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := helperCommand(t, dir, "cache", "rebuild").CombinedOutput()
+	if err != nil {
+		t.Errorf("Invocation failed: %v\ngot:  %q", err, got)
+	}
+	want := fmt.Sprintf("Rebuilt the cache in %s", cachePath)
+	if gotString := trim(string(got)); gotString != want {
+		t.Errorf("Inequal stderr/out:\nwant: %q\ngot:  %q", want, gotString)
+	}
+
+	if out, err := helperCommand(t, dir, "--date_override=2000-01-04").CombinedOutput(); err != nil {
+		t.Errorf("Invocation failed: %v\ngot:  %q", err, out)
+	}
+	assertLogEntry(t, dir, "2000-01-04", `# Andrew Allen - 2000-01-04
+
+<!-- logbook:begin -->
+
+## Reminders:
+
+From 2000-01-03:
+
+ *  Bbb (2000-01-03.md:3)
+
+<!-- logbook:end -->
+
+`)
+}
+
+func TestWarnings(t *testing.T) {
+	dir, cleanup := makeFakeHome(t)
+	defer cleanup()
+	t.Logf("Dir: %s", dir)
+
+	makeLogEntry(t, dir, "2000-01-03", "# Andrew Allen - 2000-01-03\n")
+	makeLogEntry(t, dir, "README", "# My logbook\n")
+
+	cmd := helperCommand(t, dir, "list")
+	stderr := &bytes.Buffer{}
+	cmd.Stderr = stderr
+	got, err := cmd.Output()
+	if err != nil {
+		t.Errorf("Invocation failed: %v\nstderr: %q", err, stderr)
+	}
+	if want := "2000-01-03\n"; string(got) != want {
+		t.Errorf("Inequal stdout:\nwant: %q\ngot:  %q", want, got)
+	}
+	wantStderr := "Warning: README.md: skipped since the name doesn't match the file format \"2006-01-02.md\"\n"
+	if stderr.String() != wantStderr {
+		t.Errorf("Inequal stderr:\nwant: %q\ngot:  %q", wantStderr, stderr)
+	}
+
+	// Ignored files aren't warned about.
+	err = ioutil.WriteFile(filepath.Join(dir, "logbook", ".logbook.toml"), []byte(`ignore_files = ["README.md"]`), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := helperCommand(t, dir, "list").CombinedOutput(); err != nil || string(got) != "2000-01-03\n" {
+		t.Errorf("Expected only the entry to be listed, got %q, %v", got, err)
+	}
+}
+
+func TestLintFiles(t *testing.T) {
+	dir, cleanup := makeFakeHome(t)
+	defer cleanup()
+	t.Logf("Dir: %s", dir)
+
+	makeLogEntry(t, dir, "2000-01-03", "# Andrew Allen - 2000-01-03\n\nsomeday: Clean up\n")
+	makeLogEntry(t, dir, "2000-01-04", "# Andrew Allen - 2000-01-04\n\ntomorrow: Clean up\n")
+	makeLogEntry(t, dir, "README", "# My logbook\n")
+
+	// Files are printed as they were named, like pre-commit and vim name them.
+	cmd := helperCommand(t, dir, "lint", "2000-01-03.md", "2000-01-04.md", "README.md")
+	cmd.Dir = filepath.Join(dir, "logbook")
+	got, err := cmd.Output()
+	if _, ok := err.(*exec.ExitError); !ok {
+		t.Errorf("Expected lint to fail, got %v", err)
+	}
+	want := `2000-01-03.md:3:1: no valid spec parser found for spec "someday"
+README.md: warning: skipped since the name doesn't match the file format "2006-01-02.md"
+`
+	if string(got) != want {
+		t.Errorf("Inequal stdout:\nwant: %q\ngot:  %q", want, got)
+	}
+
+	// Warnings alone don't fail.
+	cmd = helperCommand(t, dir, "lint", "2000-01-04.md", "README.md")
+	cmd.Dir = filepath.Join(dir, "logbook")
+	if err := cmd.Run(); err != nil {
+		t.Errorf("Expected lint to succeed, got %v", err)
+	}
+
+	// Unsaved changes can be read from stdin.
+	cmd = helperCommand(t, dir, "lint", "--stdin_path=2000-01-04.md", "-")
+	cmd.Dir = filepath.Join(dir, "logbook")
+	cmd.Stdin = strings.NewReader("# Andrew Allen - 2000-01-04\n\ntomorrow: Clean up\n\nlater: Rest\n")
+	got, _ = cmd.Output()
+	want = `2000-01-04.md:5:1: no valid spec parser found for spec "later"
+`
+	if string(got) != want {
+		t.Errorf("Inequal stdout:\nwant: %q\ngot:  %q", want, got)
+	}
+
+	// Files that don't exist can't be checked.
+	cmd = helperCommand(t, dir, "lint", "2000-01-05.md")
+	cmd.Dir = filepath.Join(dir, "logbook")
+	if got, err := cmd.CombinedOutput(); err == nil || !strings.Contains(string(got), "Unable to read 2000-01-05.md") {
+		t.Errorf("Expected an error, got %q, %v", got, err)
+	}
+}
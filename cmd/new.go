@@ -0,0 +1,186 @@
+/**
+ * Copyright 2018 Google LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the license.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specified language governing permissions and
+ * limitations under the License.
+ */
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/achew22/logbook/parser"
+	"github.com/achew22/logbook/templater"
+)
+
+var (
+	newRefresh bool
+	newForce   bool
+)
+
+var newCommand = &command{
+	name:    "new",
+	args:    "[date]",
+	summary: "Generates the entry for a date, today by default.",
+	help: `Generates the entry for a date, today by default, filled in with the
+reminders for that day. The date can be written any way a reminder can, like
+"tomorrow" or "next monday".
+
+If the entry already exists, --refresh regenerates the reminders and other
+generated sections in it, which are between the "<!-- logbook:begin -->" and
+"<!-- logbook:end -->" markers, and keeps everything else.`,
+	setFlags: func(fs *flag.FlagSet) {
+		fs.BoolVar(&newRefresh, "refresh", false, "Regenerates the generated sections of an existing entry, keeping everything else in it.")
+		fs.BoolVar(&newForce, "force", false, "Overwrites an existing entry, after backing it up to a .bak file.")
+	},
+	run: runNew,
+}
+
+func runNew(e *env, fs *flag.FlagSet) int {
+	if fs.NArg() > 1 {
+		return usageError(fs, "new takes at most one date")
+	}
+
+	today := e.today
+	if fs.NArg() == 1 {
+		var err error
+		today, err = parseDate(e, fs.Arg(0))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid date provided. %s\n", err)
+			return 1
+		}
+	}
+
+	var err error
+	switch {
+	case newRefresh && newForce:
+		return usageError(fs, "--refresh and --force can't be used together")
+	case newRefresh:
+		err = refresh(e, today)
+	case newForce:
+		err = backup(e.parser.PathFor(today))
+		if err == nil {
+			err = generate(e, today)
+		}
+	default:
+		err = generate(e, today)
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// generate writes the entry for d, unless there already is one.
+func generate(e *env, d parser.Date) error {
+	fmt.Fprintf(os.Stderr, "Writing log entry for %s\n", d.ToYmd())
+
+	if _, err := os.Stat(e.config.LogPath); err != nil {
+		fmt.Fprintf(os.Stderr, "Creating %s\n", e.config.LogPath)
+		err := os.MkdirAll(e.config.LogPath, 0777)
+		if err != nil {
+			return fmt.Errorf("Log path %s does not exist and could not be created", e.config.LogPath)
+		}
+	}
+
+	path := e.parser.PathFor(d)
+
+	// Depending on the file format entries can be in subdirectories.
+	if err := os.MkdirAll(filepath.Dir(path), 0777); err != nil {
+		return fmt.Errorf("Unable to create the directory for %s: %v", path, err)
+	}
+
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("A file already exists by the name %s", path)
+	}
+
+	entries, err := parse(e)
+	if err != nil {
+		return fmt.Errorf("Unable to read the logbook: %v", err)
+	}
+	text, err := templater.Print(e.config, entries, d)
+	if err != nil {
+		return fmt.Errorf("Unable to generate the log entry: %v", err)
+	}
+
+	if err := ioutil.WriteFile(path, []byte(text), 0666); err != nil {
+		return fmt.Errorf("Unable to write the log entry %s: %v", path, err)
+	}
+
+	fmt.Fprintf(os.Stderr, "Wrote file %q\n", path)
+	return nil
+}
+
+// refresh regenerates the generated sections of the entry for d, or the whole
+// entry if it doesn't exist yet.
+func refresh(e *env, d parser.Date) error {
+	path := e.parser.PathFor(d)
+	existing, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return generate(e, d)
+	} else if err != nil {
+		return fmt.Errorf("Unable to read the log entry %s: %v", path, err)
+	}
+
+	fmt.Fprintf(os.Stderr, "Refreshing log entry for %s\n", d.ToYmd())
+
+	entries, err := parse(e)
+	if err != nil {
+		return fmt.Errorf("Unable to read the logbook: %v", err)
+	}
+	text, err := templater.Print(e.config, entries, d)
+	if err != nil {
+		return fmt.Errorf("Unable to generate the log entry: %v", err)
+	}
+
+	text, err = templater.Refresh(string(existing), text)
+	if err != nil {
+		return fmt.Errorf("Unable to refresh %s: %v", path, err)
+	}
+
+	if err := ioutil.WriteFile(path, []byte(text), 0666); err != nil {
+		return fmt.Errorf("Unable to write the log entry %s: %v", path, err)
+	}
+
+	fmt.Fprintf(os.Stderr, "Wrote file %q\n", path)
+	return nil
+}
+
+// backup moves path out of the way to path.bak, if it exists. Earlier backups
+// are kept, so later ones go to path.bak.1, path.bak.2 and so on.
+func backup(path string) error {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return nil
+	}
+
+	to := path + ".bak"
+	for i := 1; ; i++ {
+		if _, err := os.Lstat(to); os.IsNotExist(err) {
+			break
+		} else if err != nil {
+			return fmt.Errorf("Unable to back up %s: %v", path, err)
+		}
+		to = fmt.Sprintf("%s.bak.%d", path, i)
+	}
+
+	if err := os.Rename(path, to); err != nil {
+		return fmt.Errorf("Unable to back up %s: %v", path, err)
+	}
+	fmt.Fprintf(os.Stderr, "Backed up %s to %s\n", path, to)
+	return nil
+}
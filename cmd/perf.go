@@ -0,0 +1,61 @@
+/**
+ * Copyright 2018 Google LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the license.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specified language governing permissions and
+ * limitations under the License.
+ */
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/achew22/logbook/parser"
+	"github.com/achew22/logbook/templater"
+)
+
+var perfQuarter string
+
+var perfCommand = &command{
+	name:    "perf",
+	summary: "Lists the perf notes for a quarter.",
+	help:    `Lists every "perf:" note written during a quarter, the current one by default.`,
+	setFlags: func(fs *flag.FlagSet) {
+		fs.StringVar(&perfQuarter, "quarter", "", "The quarter to list perf notes for taking the form \"yyyyQn\". Defaults to the current quarter. Example --quarter=2026Q3")
+	},
+	run: runPerf,
+}
+
+func runPerf(e *env, fs *flag.FlagSet) int {
+	if fs.NArg() != 0 {
+		return usageError(fs, "perf doesn't take any arguments")
+	}
+
+	q := parser.QuarterOf(e.today)
+	if perfQuarter != "" {
+		var err error
+		q, err = parser.ParseQuarter(perfQuarter)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid --quarter provided. %s\n", err)
+			return 1
+		}
+	}
+
+	entries, err := parse(e)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Unable to read the logbook: %v\n", err)
+		return 1
+	}
+	fmt.Print(templater.PrintPerf(entries, q))
+	return 0
+}
@@ -0,0 +1,137 @@
+/**
+ * Copyright 2018 Google LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the license.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specified language governing permissions and
+ * limitations under the License.
+ */
+package main
+
+import (
+	"net/url"
+	"path/filepath"
+)
+
+// The parts of the SARIF 2.1.0 format that lint uses. The full format is
+// described at https://docs.oasis-open.org/sarif/sarif/v2.1.0/sarif-v2.1.0.html.
+type sarif struct {
+	Schema  string      `json:"$schema"`
+	Version string      `json:"version"`
+	Runs    []*sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    *sarifTool     `json:"tool"`
+	Results []*sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver *sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string       `json:"name"`
+	InformationURI string       `json:"informationUri"`
+	Rules          []*sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string        `json:"id"`
+	ShortDescription *sarifMessage `json:"shortDescription"`
+}
+
+type sarifResult struct {
+	RuleID    string           `json:"ruleId"`
+	Level     string           `json:"level"`
+	Message   *sarifMessage    `json:"message"`
+	Locations []*sarifLocation `json:"locations"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation *sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation *sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion           `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+}
+
+// Every diagnostic of a severity has the same rule.
+var sarifRules = map[string]*sarifRule{
+	severityError: {
+		ID:               "parse-error",
+		ShortDescription: &sarifMessage{Text: "A line in an entry couldn't be parsed."},
+	},
+	severityWarning: {
+		ID:               "skipped-file",
+		ShortDescription: &sarifMessage{Text: "A file in the logbook couldn't be parsed at all."},
+	},
+}
+
+func sarifLog(diagnostics []*diagnostic) *sarif {
+	run := &sarifRun{
+		Tool: &sarifTool{
+			Driver: &sarifDriver{
+				Name:           "logbook",
+				InformationURI: "https://github.com/achew22/logbook",
+				Rules:          []*sarifRule{sarifRules[severityError], sarifRules[severityWarning]},
+			},
+		},
+		// Results are required, even if there aren't any.
+		Results: []*sarifResult{},
+	}
+
+	for _, d := range diagnostics {
+		location := &sarifPhysicalLocation{
+			ArtifactLocation: &sarifArtifactLocation{URI: sarifURI(d.Path)},
+		}
+		if d.Line > 0 {
+			location.Region = &sarifRegion{
+				StartLine:   d.Line,
+				StartColumn: d.Column,
+			}
+		}
+		run.Results = append(run.Results, &sarifResult{
+			RuleID:    sarifRules[d.Severity].ID,
+			Level:     d.Severity,
+			Message:   &sarifMessage{Text: d.Message},
+			Locations: []*sarifLocation{{PhysicalLocation: location}},
+		})
+	}
+
+	return &sarif{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs:    []*sarifRun{run},
+	}
+}
+
+// sarifURI turns a path into a URI, which is relative if the path is.
+func sarifURI(path string) string {
+	uri := &url.URL{Path: filepath.ToSlash(path)}
+	if filepath.IsAbs(path) {
+		uri.Scheme = "file"
+	}
+	return uri.String()
+}
@@ -0,0 +1,89 @@
+/**
+ * Copyright 2018 Google LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the license.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specified language governing permissions and
+ * limitations under the License.
+ */
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+var searchRegexp bool
+
+var searchCommand = &command{
+	name:    "search",
+	args:    "<query>",
+	summary: "Finds the lines in every entry that match a query.",
+	help: `Finds the lines in every entry that contain the query, ignoring case, and
+prints them as "file:line: text" oldest first. Exits with 1 if nothing matches.`,
+	setFlags: func(fs *flag.FlagSet) {
+		fs.BoolVar(&searchRegexp, "regexp", false, "Treats the query as a regular expression.")
+	},
+	run: runSearch,
+}
+
+func runSearch(e *env, fs *flag.FlagSet) int {
+	if fs.NArg() == 0 {
+		return usageError(fs, "search needs a query")
+	}
+
+	query := strings.Join(fs.Args(), " ")
+	if !searchRegexp {
+		query = regexp.QuoteMeta(query)
+	}
+	re, err := regexp.Compile("(?i)" + query)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid query provided. %s\n", err)
+		return 1
+	}
+
+	entries, err := parse(e)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Unable to read the logbook: %v\n", err)
+		return 1
+	}
+
+	found := false
+	for _, entry := range entriesOnDisk(entries) {
+		f, err := os.Open(entry.Path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Unable to read %s: %v\n", entry.Path, err)
+			return 1
+		}
+
+		scanner := bufio.NewScanner(f)
+		for line := 1; scanner.Scan(); line++ {
+			if re.MatchString(scanner.Text()) {
+				fmt.Printf("%s:%d: %s\n", relPath(e, entry.Path), line, scanner.Text())
+				found = true
+			}
+		}
+		f.Close()
+
+		if err := scanner.Err(); err != nil {
+			fmt.Fprintf(os.Stderr, "Unable to read %s: %v\n", entry.Path, err)
+			return 1
+		}
+	}
+
+	if !found {
+		return 1
+	}
+	return 0
+}
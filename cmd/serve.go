@@ -0,0 +1,73 @@
+/**
+ * Copyright 2018 Google LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the license.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specified language governing permissions and
+ * limitations under the License.
+ */
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/achew22/logbook/server"
+)
+
+var (
+	serveAddr     string
+	servePollRate time.Duration
+	serveWrites   bool
+)
+
+var serveCommand = &command{
+	name:    "serve",
+	summary: "Serves the logbook as web pages.",
+	help: `Serves a read-only view of the logbook over HTTP: every entry, the upcoming
+reminders and the parse errors. Changes to the logbook show up without
+restarting the server.
+
+The same data is available as JSON under /api/v1/. With --allow_writes, notes
+can also be added to entries with POST /api/v1/entries/{date}/notes.`,
+	setFlags: func(fs *flag.FlagSet) {
+		fs.StringVar(&serveAddr, "addr", "localhost:8080", "The address to listen on. Use \":8080\" to share the logbook with other machines.")
+		fs.DurationVar(&servePollRate, "poll", 2*time.Second, "How often to check the logbook for changes.")
+		fs.BoolVar(&serveWrites, "allow_writes", false, "Allows notes to be added to entries through the API. Anyone who can reach --addr can write to the logbook.")
+	},
+	run: runServe,
+}
+
+func runServe(e *env, fs *flag.FlagSet) int {
+	if fs.NArg() != 0 {
+		return usageError(fs, "serve doesn't take any arguments")
+	}
+
+	s := server.New(e.config)
+	s.AllowWrites = serveWrites
+	if e.cachePath != "" {
+		s.UseCache(e.cachePath)
+	}
+	if _, err := s.Reload(); err != nil {
+		fmt.Fprintf(os.Stderr, "Unable to read the logbook: %v\n", err)
+		return 1
+	}
+	go s.Watch(servePollRate, nil)
+
+	fmt.Fprintf(os.Stderr, "Serving %s on http://%s\n", e.config.LogPath, serveAddr)
+	if err := http.ListenAndServe(serveAddr, s); err != nil {
+		fmt.Fprintf(os.Stderr, "Unable to serve: %v\n", err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,66 @@
+/**
+ * Copyright 2018 Google LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the license.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specified language governing permissions and
+ * limitations under the License.
+ */
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+)
+
+var showCommand = &command{
+	name:    "show",
+	args:    "<date>",
+	summary: "Prints the entry for a date.",
+	help: `Prints the entry for a date. The date can be written any way a reminder can,
+like "yesterday" or "friday".`,
+	run: runShow,
+}
+
+func runShow(e *env, fs *flag.FlagSet) int {
+	if fs.NArg() != 1 {
+		return usageError(fs, "show takes exactly one date")
+	}
+
+	d, err := parseDate(e, fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid date provided. %s\n", err)
+		return 1
+	}
+
+	// Entries named by hand aren't always where PathFor expects, so look
+	// for the file the entry was read from.
+	entries, err := parse(e)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Unable to read the logbook: %v\n", err)
+		return 1
+	}
+	entry, ok := entries[d]
+	if !ok || !entry.OnDisk {
+		fmt.Fprintf(os.Stderr, "There is no entry for %s\n", d.ToYmd())
+		return 1
+	}
+
+	contents, err := ioutil.ReadFile(entry.Path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Unable to read the entry for %s: %v\n", d.ToYmd(), err)
+		return 1
+	}
+
+	os.Stdout.Write(contents)
+	return 0
+}
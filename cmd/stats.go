@@ -0,0 +1,78 @@
+/**
+ * Copyright 2018 Google LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the license.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specified language governing permissions and
+ * limitations under the License.
+ */
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+)
+
+var statsCommand = &command{
+	name:    "stats",
+	summary: "Summarizes what is in the logbook.",
+	help:    "Counts the entries in the logbook and everything written in them.",
+	run:     runStats,
+}
+
+func runStats(e *env, fs *flag.FlagSet) int {
+	if fs.NArg() != 0 {
+		return usageError(fs, "stats doesn't take any arguments")
+	}
+
+	entries, err := parse(e)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Unable to read the logbook: %v\n", err)
+		return 1
+	}
+	onDisk := entriesOnDisk(entries)
+	if len(onDisk) == 0 {
+		fmt.Println("There are no entries")
+		return 0
+	}
+
+	var reminders, recurring, openItems, todos, actionItems, perfNotes, errors int
+	for _, entry := range entries {
+		for _, messages := range entry.PastReferences {
+			reminders += len(messages)
+		}
+		recurring += len(entry.Recurrences)
+		openItems += len(entry.OpenItems)
+		todos += len(entry.Todos)
+		actionItems += len(entry.ActionItems)
+		perfNotes += len(entry.PerfNotes)
+		errors += len(entry.Errors)
+	}
+
+	for _, stat := range []struct {
+		name  string
+		value interface{}
+	}{
+		{"Entries", len(onDisk)},
+		{"First entry", onDisk[0].Date.ToYmd()},
+		{"Last entry", onDisk[len(onDisk)-1].Date.ToYmd()},
+		{"Reminders", reminders},
+		{"Recurring reminders", recurring},
+		{"Open items", openItems},
+		{"TODOs", todos},
+		{"Action items", actionItems},
+		{"Perf notes", perfNotes},
+		{"Parse errors", errors},
+	} {
+		fmt.Printf("%-20s %v\n", stat.name+":", stat.value)
+	}
+	return 0
+}
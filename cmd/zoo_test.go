@@ -1,12 +1,18 @@
 package main
 
 import (
+	"bytes"
 	"flag"
 	"fmt"
 	"io/ioutil"
+	"os"
+	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
 
+	"github.com/google/go-cmp/cmp"
+
 	"github.com/achew22/logbook/config"
 	"github.com/achew22/logbook/parser"
 )
@@ -56,6 +62,7 @@ func testCase(t *testing.T, dir string) {
 		// Iterate over the directory and copy all .md and .out files to the
 		// temporary home directory. That way modification isn't destructive.
 		var toGenerate []parser.Date
+		var toRun []string
 		for _, file := range files {
 			if file.IsDir() {
 				continue
@@ -77,6 +84,10 @@ func testCase(t *testing.T, dir string) {
 				s := filepath.Join(dir, name)
 				d := filepath.Join(logPath, name)
 				cp(t, s, d)
+			case ".args":
+				toRun = append(toRun, name[0:len(name)-len(".args")])
+			case ".stdout":
+				// Goldens for the .args files.
 			default:
 				t.Logf("%s was not copied", name)
 			}
@@ -92,18 +103,26 @@ func testCase(t *testing.T, dir string) {
 				cp(t, src, dst)
 			}
 		}
+
+		for _, name := range toRun {
+			runAndCompare(t, dir, homeDir, name)
+		}
 	})
 }
 
 func generateAndCompare(t *testing.T, logPath string, d parser.Date) {
 	t.Run(d.ToYmd(), func(t *testing.T) {
-		// Invoke the main function on the date
+		// Invoke the main function on the date. Entries that were written by
+		// hand are refreshed instead.
 		homeDir := filepath.Dir(logPath)
-		out, err := helperCommand(
-			t, homeDir,
-			"--date_override="+d.ToYmd(),
+		args := []string{
+			"--date_override=" + d.ToYmd(),
 			"--name_override=Andrew Allen",
-		).CombinedOutput()
+		}
+		if _, err := os.Stat(filepath.Join(logPath, d.ToYmd()+".md")); err == nil {
+			args = append(args, "new", "--refresh")
+		}
+		out, err := helperCommand(t, homeDir, args...).CombinedOutput()
 		if err != nil {
 			t.Errorf("Unable to run generator: %v\nOutput:%s", err, out)
 		}
@@ -118,3 +137,54 @@ func generateAndCompare(t *testing.T, logPath string, d parser.Date) {
 		assertLogEntry(t, homeDir, d.ToYmd(), string(want))
 	})
 }
+
+// runAndCompare runs logbook with the arguments in the name.args file, one per
+// line, and compares what it prints to stdout with the name.stdout file. If it
+// exits unsuccessfully, the exit status is the last line of the output. The
+// fake home directory is written as $HOME.
+func runAndCompare(t *testing.T, dir, homeDir, name string) {
+	t.Run(name, func(t *testing.T) {
+		contents, err := ioutil.ReadFile(filepath.Join(dir, name+".args"))
+		if err != nil {
+			t.Fatalf("Unable to read file: %v", err)
+		}
+
+		var args []string
+		if s := trim(string(contents)); s != "" {
+			args = strings.Split(s, "\n")
+		}
+
+		cmd := helperCommand(t, homeDir, args...)
+		stdout := &bytes.Buffer{}
+		stderr := &bytes.Buffer{}
+		cmd.Stdout = stdout
+		cmd.Stderr = stderr
+		if err := cmd.Run(); err != nil {
+			if _, ok := err.(*exec.ExitError); !ok {
+				t.Fatalf("Unable to run logbook: %v", err)
+			}
+			fmt.Fprintf(stdout, "%v\n", err)
+		}
+		t.Logf("Stderr: %s", stderr)
+
+		// The fake home directory is different every time.
+		got := strings.Replace(stdout.String(), homeDir, "$HOME", -1)
+
+		goldenPath := filepath.Join(dir, name+".stdout")
+		if *updateGoldens {
+			t.Logf("Writing: %s", goldenPath)
+			if err := ioutil.WriteFile(goldenPath, []byte(got), 0644); err != nil {
+				t.Fatal(err)
+			}
+			return
+		}
+
+		want, err := ioutil.ReadFile(goldenPath)
+		if err != nil {
+			t.Fatalf("Unable to read file: %v", err)
+		}
+		if diff := cmp.Diff(strings.Split(got, "\n"), strings.Split(string(want), "\n")); diff != "" {
+			t.Errorf("Diff for %s:\n!!! (- = got, + = want)\n%s\nGot: %q", goldenPath, diff, got)
+		}
+	})
+}
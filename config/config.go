@@ -15,7 +15,90 @@
  */
 package config
 
+import (
+	"time"
+)
+
+// DefaultFileFormat names entries after their date, like "2000-01-02.md".
+const DefaultFileFormat = "2006-01-02.md"
+
 type Config struct {
 	Name    string
 	LogPath string
+
+	// FileFormat is the time layout, relative to LogPath, that entries are
+	// named with. See DefaultFileFormat.
+	FileFormat string
+
+	// TemplatePath is a file to render generated entries with instead of the
+	// built-in template.
+	TemplatePath string
+
+	// Editor is the command "logbook edit" opens entries with. Defaults to
+	// $VISUAL, then $EDITOR.
+	Editor string
+
+	// EditorArgs are passed to the Editor before the entries. For vim they
+	// default to "+windo set nonumber", "+wincmd k" and "-o", which open
+	// them in split windows without line numbers, starting in the first.
+	EditorArgs []string
+
+	// PerfRollupDays is how many days before the end of a quarter the perf
+	// notes for the quarter are added to generated entries. Negative values
+	// disable the roll-up.
+	PerfRollupDays int
+
+	// WorkingDays are the days of the week counted by "in N business days".
+	// Defaults to Monday through Friday.
+	WorkingDays []time.Weekday
+
+	// SkipNonWorkingDays moves reminders that fall on a day that isn't one of
+	// the WorkingDays forward to the next working day.
+	SkipNonWorkingDays bool
+
+	// NewestFirst lists reminders and parse errors in generated entries from
+	// newest to oldest instead of oldest to newest.
+	NewestFirst bool
+
+	// Ignore are the instructions which are never parsed as reminders, on top
+	// of the DefaultIgnoreRules.
+	Ignore IgnoreRules
+
+	// IgnoreFiles are globs, like "README.md" or "drafts/*", for files and
+	// directories in the logbook which aren't parsed. They are matched against
+	// the path relative to the logbook, or just the name for globs without a
+	// "/".
+	IgnoreFiles []string
+}
+
+// IgnoreRules describe instructions, the text before the colon in
+// "tomorrow: do stuff", which aren't parsed as reminders. Words and prefixes
+// are matched case insensitively.
+type IgnoreRules struct {
+	// SkipDefaults stops the DefaultIgnoreRules from being used.
+	SkipDefaults bool
+
+	// Words are ignored when the instruction is exactly the word, like "note".
+	Words []string
+
+	// Prefixes are ignored when the instruction starts with the prefix.
+	Prefixes []string
+
+	// Patterns are regular expressions which are ignored when they match the
+	// instruction.
+	Patterns []string
+}
+
+var DefaultIgnoreRules = IgnoreRules{
+	Words: []string{
+		// Urls are often in notes.
+		"http",
+		"https",
+		// Sometimes notes are prefaced with the literal "note".
+		"note",
+	},
+	Prefixes: []string{
+		// Bazel targets like //foo:bar.
+		"//",
+	},
 }
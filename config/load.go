@@ -0,0 +1,286 @@
+/**
+ * Copyright 2018 Google LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the license.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specified language governing permissions and
+ * limitations under the License.
+ */
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+// Error is a problem with a single key in a config file.
+type Error struct {
+	Path    string
+	Key     string
+	Message string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s: %s: %s", e.Path, e.Key, e.Message)
+}
+
+// file is the on disk representation of a Config. Pointers distinguish keys
+// that were left out from ones set to their zero value.
+type file struct {
+	Name               *string     `toml:"name"`
+	LogPath            *string     `toml:"log_path"`
+	FileFormat         *string     `toml:"file_format"`
+	TemplatePath       *string     `toml:"template_path"`
+	Editor             *string     `toml:"editor"`
+	EditorArgs         []string    `toml:"editor_args"`
+	PerfRollupDays     *int        `toml:"perf_rollup_days"`
+	WorkingDays        []string    `toml:"working_days"`
+	SkipNonWorkingDays *bool       `toml:"skip_non_working_days"`
+	NewestFirst        *bool       `toml:"newest_first"`
+	Ignore             *ignoreFile `toml:"ignore"`
+	IgnoreFiles        []string    `toml:"ignore_files"`
+}
+
+type ignoreFile struct {
+	Defaults *bool    `toml:"defaults"`
+	Words    []string `toml:"words"`
+	Prefixes []string `toml:"prefixes"`
+	Patterns []string `toml:"patterns"`
+}
+
+// logbookFile is a config file in the logbook directory which adds settings
+// specific to that logbook.
+type logbookFile struct {
+	Ignore      *ignoreFile `toml:"ignore"`
+	IgnoreFiles []string    `toml:"ignore_files"`
+}
+
+// LogbookFileName is the name of the config file in the logbook directory.
+const LogbookFileName = ".logbook.toml"
+
+var weekdays = map[string]time.Weekday{
+	"sunday":    time.Sunday,
+	"sun":       time.Sunday,
+	"monday":    time.Monday,
+	"mon":       time.Monday,
+	"tuesday":   time.Tuesday,
+	"tue":       time.Tuesday,
+	"wednesday": time.Wednesday,
+	"wed":       time.Wednesday,
+	"thursday":  time.Thursday,
+	"thu":       time.Thursday,
+	"friday":    time.Friday,
+	"fri":       time.Friday,
+	"saturday":  time.Saturday,
+	"sat":       time.Saturday,
+}
+
+// Default is the Config used for anything that isn't in the config file.
+func Default() *Config {
+	return &Config{
+		Name:           "Andrew Allen",
+		LogPath:        os.ExpandEnv("${HOME}/logbook"),
+		FileFormat:     DefaultFileFormat,
+		PerfRollupDays: 14,
+	}
+}
+
+// Path returns where the config file is read from. $LOGBOOK_CONFIG takes
+// precedence, then $XDG_CONFIG_HOME/logbook/config.toml and finally
+// $HOME/.config/logbook/config.toml.
+func Path() string {
+	if p := os.Getenv("LOGBOOK_CONFIG"); p != "" {
+		return p
+	}
+	if xdg := os.Getenv("XDG_CONFIG_HOME"); xdg != "" {
+		return filepath.Join(xdg, "logbook", "config.toml")
+	}
+	return os.ExpandEnv(filepath.Join("${HOME}", ".config", "logbook", "config.toml"))
+}
+
+// Load reads the config file at Path() on top of Default(), followed by the
+// LogbookFileName in the logbook directory. It is fine for there to be no
+// config file, unless $LOGBOOK_CONFIG points to one.
+func Load() (*Config, error) {
+	c := Default()
+
+	path := Path()
+	if _, err := os.Stat(path); !os.IsNotExist(err) || os.Getenv("LOGBOOK_CONFIG") != "" {
+		if err := LoadFile(c, path); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := LoadLogbookFile(c); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// LoadFile reads the config file at path, overwriting any values in c that
+// it sets.
+func LoadFile(c *Config, path string) error {
+	var f file
+	md, err := toml.DecodeFile(path, &f)
+	if err != nil {
+		return fmt.Errorf("%s: %v", path, err)
+	}
+
+	if undecoded := md.Undecoded(); len(undecoded) > 0 {
+		return &Error{
+			Path:    path,
+			Key:     undecoded[0].String(),
+			Message: "unknown key",
+		}
+	}
+
+	if f.Name != nil {
+		c.Name = *f.Name
+	}
+	if f.LogPath != nil {
+		c.LogPath = expandPath(*f.LogPath)
+	}
+	if f.FileFormat != nil {
+		if err := validateFileFormat(*f.FileFormat); err != nil {
+			return &Error{Path: path, Key: "file_format", Message: err.Error()}
+		}
+		c.FileFormat = *f.FileFormat
+	}
+	if f.TemplatePath != nil {
+		c.TemplatePath = expandPath(*f.TemplatePath)
+	}
+	if f.Editor != nil {
+		c.Editor = *f.Editor
+	}
+	if f.EditorArgs != nil {
+		c.EditorArgs = f.EditorArgs
+	}
+	if f.PerfRollupDays != nil {
+		c.PerfRollupDays = *f.PerfRollupDays
+	}
+	if f.WorkingDays != nil {
+		c.WorkingDays = nil
+		for _, name := range f.WorkingDays {
+			day, ok := weekdays[strings.ToLower(name)]
+			if !ok {
+				return &Error{Path: path, Key: "working_days", Message: fmt.Sprintf("unknown day of the week %q", name)}
+			}
+			c.WorkingDays = append(c.WorkingDays, day)
+		}
+		if len(c.WorkingDays) == 0 {
+			return &Error{Path: path, Key: "working_days", Message: "there must be at least one working day"}
+		}
+	}
+	if f.SkipNonWorkingDays != nil {
+		c.SkipNonWorkingDays = *f.SkipNonWorkingDays
+	}
+	if f.NewestFirst != nil {
+		c.NewestFirst = *f.NewestFirst
+	}
+	if f.Ignore != nil {
+		if err := loadIgnore(c, path, f.Ignore); err != nil {
+			return err
+		}
+	}
+	if err := loadIgnoreFiles(c, path, f.IgnoreFiles); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// LoadLogbookFile reads the LogbookFileName in c.LogPath if there is one,
+// adding its settings to c.
+func LoadLogbookFile(c *Config) error {
+	path := filepath.Join(c.LogPath, LogbookFileName)
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return nil
+	}
+
+	var f logbookFile
+	md, err := toml.DecodeFile(path, &f)
+	if err != nil {
+		return fmt.Errorf("%s: %v", path, err)
+	}
+
+	if undecoded := md.Undecoded(); len(undecoded) > 0 {
+		return &Error{
+			Path:    path,
+			Key:     undecoded[0].String(),
+			Message: "unknown key",
+		}
+	}
+
+	if f.Ignore != nil {
+		if err := loadIgnore(c, path, f.Ignore); err != nil {
+			return err
+		}
+	}
+	return loadIgnoreFiles(c, path, f.IgnoreFiles)
+}
+
+// loadIgnore adds the rules in f to the ones already in c.
+func loadIgnore(c *Config, path string, f *ignoreFile) error {
+	for _, p := range f.Patterns {
+		if _, err := regexp.Compile(p); err != nil {
+			return &Error{Path: path, Key: "ignore.patterns", Message: err.Error()}
+		}
+	}
+
+	if f.Defaults != nil {
+		c.Ignore.SkipDefaults = !*f.Defaults
+	}
+	c.Ignore.Words = append(c.Ignore.Words, f.Words...)
+	c.Ignore.Prefixes = append(c.Ignore.Prefixes, f.Prefixes...)
+	c.Ignore.Patterns = append(c.Ignore.Patterns, f.Patterns...)
+	return nil
+}
+
+// loadIgnoreFiles adds globs to the ones already in c.
+func loadIgnoreFiles(c *Config, path string, globs []string) error {
+	for _, g := range globs {
+		if _, err := filepath.Match(g, ""); err != nil {
+			return &Error{Path: path, Key: "ignore_files", Message: fmt.Sprintf("%q: %v", g, err)}
+		}
+	}
+	c.IgnoreFiles = append(c.IgnoreFiles, globs...)
+	return nil
+}
+
+// expandPath expands environment variables and a leading "~" in p.
+func expandPath(p string) string {
+	p = os.ExpandEnv(p)
+	if p == "~" || strings.HasPrefix(p, "~/") {
+		p = os.Getenv("HOME") + p[1:]
+	}
+	return p
+}
+
+// validateFileFormat checks that format includes the year, month and day so
+// that every date gets its own file which can be turned back into the date.
+func validateFileFormat(format string) error {
+	want := time.Date(2001, time.February, 3, 0, 0, 0, 0, time.UTC)
+	got, err := time.Parse(format, want.Format(format))
+	if err != nil || !got.Equal(want) {
+		return fmt.Errorf("%q must include the year, month and day", format)
+	}
+	// Only files with the extension are entries, so without one every file
+	// would be.
+	if ext := filepath.Ext(format); ext == "" || filepath.Ext(want.Format(format)) != ext {
+		return fmt.Errorf("%q must end in an extension, like .md", format)
+	}
+	return nil
+}
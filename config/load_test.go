@@ -0,0 +1,212 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func writeConfig(t *testing.T, contents string) (string, func()) {
+	dir, err := ioutil.TempDir("", "logbook")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(dir, "config.toml")
+	if err := ioutil.WriteFile(path, []byte(contents), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path, func() {
+		os.RemoveAll(dir)
+	}
+}
+
+func TestLoadFile(t *testing.T) {
+	path, cleanup := writeConfig(t, `
+name = "Joe Armstrong"
+log_path = "~/notes"
+file_format = "2006/01-02.md"
+template_path = "/etc/logbook/template.md"
+editor = "code --wait"
+editor_args = ["-O"]
+perf_rollup_days = 30
+working_days = ["Sunday", "mon", "tue", "wed", "thu"]
+skip_non_working_days = true
+newest_first = true
+ignore_files = ["README.md", "drafts/*"]
+
+[ignore]
+defaults = false
+words = ["re", "q"]
+prefixes = ["error"]
+patterns = ["^json$"]
+`)
+	defer cleanup()
+
+	c := Default()
+	if err := LoadFile(c, path); err != nil {
+		t.Fatal(err)
+	}
+
+	want := &Config{
+		Name:               "Joe Armstrong",
+		LogPath:            os.Getenv("HOME") + "/notes",
+		FileFormat:         "2006/01-02.md",
+		TemplatePath:       "/etc/logbook/template.md",
+		Editor:             "code --wait",
+		EditorArgs:         []string{"-O"},
+		PerfRollupDays:     30,
+		WorkingDays:        []time.Weekday{time.Sunday, time.Monday, time.Tuesday, time.Wednesday, time.Thursday},
+		SkipNonWorkingDays: true,
+		NewestFirst:        true,
+		Ignore: IgnoreRules{
+			SkipDefaults: true,
+			Words:        []string{"re", "q"},
+			Prefixes:     []string{"error"},
+			Patterns:     []string{"^json$"},
+		},
+		IgnoreFiles: []string{"README.md", "drafts/*"},
+	}
+	if diff := cmp.Diff(c, want); diff != "" {
+		t.Errorf("Differences:\n%s", diff)
+	}
+}
+
+func TestLoadFileKeepsDefaults(t *testing.T) {
+	path, cleanup := writeConfig(t, `name = "Joe Armstrong"`)
+	defer cleanup()
+
+	c := Default()
+	if err := LoadFile(c, path); err != nil {
+		t.Fatal(err)
+	}
+
+	want := Default()
+	want.Name = "Joe Armstrong"
+	if diff := cmp.Diff(c, want); diff != "" {
+		t.Errorf("Differences:\n%s", diff)
+	}
+}
+
+func TestLoadFileErrors(t *testing.T) {
+	tests := map[string]struct {
+		contents string
+		want     string
+	}{
+		"Unknown key":          {`nmae = "Joe"`, `nmae: unknown key`},
+		"Unknown working day":  {`working_days = ["mon", "funday"]`, `working_days: unknown day of the week "funday"`},
+		"No working days":      {`working_days = []`, `working_days: there must be at least one working day`},
+		"File format sans day": {`file_format = "2006-01.md"`, `file_format: "2006-01.md" must include the year, month and day`},
+		"File format sans ext": {`file_format = "2006/01-02"`, `file_format: "2006/01-02" must end in an extension, like .md`},
+		"Date as extension":    {`file_format = "2006.01.02"`, `file_format: "2006.01.02" must end in an extension, like .md`},
+		"Invalid pattern":      {"[ignore]\npatterns = [\"(\"]", "ignore.patterns: error parsing regexp: missing closing ): `(`"},
+		"Unknown ignore key":   {"[ignore]\nprefix = [\"re\"]", "ignore.prefix: unknown key"},
+		"Invalid glob":         {`ignore_files = ["["]`, `ignore_files: "[": syntax error in pattern`},
+	}
+	for n, test := range tests {
+		t.Run(n, func(t *testing.T) {
+			path, cleanup := writeConfig(t, test.contents)
+			defer cleanup()
+
+			err := LoadFile(Default(), path)
+			if err == nil {
+				t.Fatalf("Expected an error but didn't get one")
+			}
+			if want := path + ": " + test.want; err.Error() != want {
+				t.Errorf("Expected error to be [%s], was [%v]", want, err)
+			}
+		})
+	}
+}
+
+func TestLoadMissingFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "logbook")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	defer os.Setenv("XDG_CONFIG_HOME", os.Getenv("XDG_CONFIG_HOME"))
+	defer os.Setenv("LOGBOOK_CONFIG", os.Getenv("LOGBOOK_CONFIG"))
+
+	os.Setenv("XDG_CONFIG_HOME", dir)
+	os.Setenv("LOGBOOK_CONFIG", "")
+	if _, err := Load(); err != nil {
+		t.Errorf("Expected a missing config file to be fine, got %v", err)
+	}
+
+	os.Setenv("LOGBOOK_CONFIG", filepath.Join(dir, "missing.toml"))
+	if _, err := Load(); err == nil {
+		t.Errorf("Expected a missing $LOGBOOK_CONFIG to be an error")
+	}
+}
+
+func TestPath(t *testing.T) {
+	defer os.Setenv("XDG_CONFIG_HOME", os.Getenv("XDG_CONFIG_HOME"))
+	defer os.Setenv("LOGBOOK_CONFIG", os.Getenv("LOGBOOK_CONFIG"))
+
+	os.Setenv("LOGBOOK_CONFIG", "")
+	os.Setenv("XDG_CONFIG_HOME", "")
+	if got, want := Path(), filepath.Join(os.Getenv("HOME"), ".config", "logbook", "config.toml"); got != want {
+		t.Errorf("Expected the default path to be %q, was %q", want, got)
+	}
+
+	os.Setenv("XDG_CONFIG_HOME", "/xdg")
+	if got, want := Path(), "/xdg/logbook/config.toml"; got != want {
+		t.Errorf("Expected the XDG path to be %q, was %q", want, got)
+	}
+
+	os.Setenv("LOGBOOK_CONFIG", "/override.toml")
+	if got, want := Path(), "/override.toml"; got != want {
+		t.Errorf("Expected the $LOGBOOK_CONFIG path to be %q, was %q", want, got)
+	}
+}
+
+func TestLoadLogbookFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "logbook")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	err = ioutil.WriteFile(filepath.Join(dir, LogbookFileName), []byte(`
+ignore_files = ["README.md"]
+
+[ignore]
+words = ["json"]
+`), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := Default()
+	c.LogPath = dir
+	c.Ignore.Words = []string{"re"}
+	if err := LoadLogbookFile(c); err != nil {
+		t.Fatal(err)
+	}
+
+	if diff := cmp.Diff(c.Ignore.Words, []string{"re", "json"}); diff != "" {
+		t.Errorf("Differences:\n%s", diff)
+	}
+	if diff := cmp.Diff(c.IgnoreFiles, []string{"README.md"}); diff != "" {
+		t.Errorf("Differences:\n%s", diff)
+	}
+
+	// Only ignore rules belong in the logbook's config file.
+	err = ioutil.WriteFile(filepath.Join(dir, LogbookFileName), []byte(`name = "Joe"`), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := filepath.Join(dir, LogbookFileName) + ": name: unknown key"
+	if err := LoadLogbookFile(Default()); err != nil {
+		t.Errorf("Expected the default log path to not have a logbook file, got %v", err)
+	}
+	if err := LoadLogbookFile(c); err == nil || err.Error() != want {
+		t.Errorf("Expected error to be [%s], was [%v]", want, err)
+	}
+}
@@ -0,0 +1,130 @@
+/**
+ * Copyright 2018 Google LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the license.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specified language governing permissions and
+ * limitations under the License.
+ */
+// Package ics reads and writes the parts of iCalendar (RFC 5545) that logbook
+// needs to share reminders with calendar apps.
+package ics
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// ProdID identifies logbook as the producer of a calendar.
+const ProdID = "-//achew22//logbook//EN"
+
+// maxLineLength is how many octets a content line can be before it has to be
+// folded onto the next line.
+const maxLineLength = 75
+
+// Event is a VEVENT.
+type Event struct {
+	UID         string
+	Summary     string
+	Description string
+
+	// Start is when the event starts. For all day events only the date is
+	// used.
+	Start  time.Time
+	AllDay bool
+
+	// Stamp is when the event was created, which is required by the format.
+	Stamp time.Time
+}
+
+// textEscaper escapes the characters that have special meaning in TEXT
+// values.
+var textEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	";", `\;`,
+	",", `\,`,
+	"\r\n", `\n`,
+	"\n", `\n`,
+)
+
+type writer struct {
+	w   *bufio.Writer
+	err error
+}
+
+// line writes a content line, folding it if it is too long.
+func (w *writer) line(name, value string) {
+	if w.err != nil {
+		return
+	}
+
+	line := name + ":" + value
+	for len(line) > maxLineLength {
+		// Don't split a multi-byte character.
+		i := maxLineLength
+		for i > 0 && !isRuneStart(line[i]) {
+			i--
+		}
+		_, w.err = w.w.WriteString(line[:i] + "\r\n")
+		if w.err != nil {
+			return
+		}
+		// Continuation lines start with a space, which counts towards
+		// their length.
+		line = " " + line[i:]
+	}
+	_, w.err = w.w.WriteString(line + "\r\n")
+}
+
+func isRuneStart(b byte) bool {
+	return b&0xC0 != 0x80
+}
+
+// Write writes a VCALENDAR containing events to w.
+func Write(w io.Writer, events []*Event) error {
+	out := &writer{w: bufio.NewWriter(w)}
+
+	out.line("BEGIN", "VCALENDAR")
+	out.line("VERSION", "2.0")
+	out.line("PRODID", ProdID)
+	out.line("CALSCALE", "GREGORIAN")
+	for _, e := range events {
+		out.line("BEGIN", "VEVENT")
+		out.line("UID", e.UID)
+		out.line("DTSTAMP", e.Stamp.UTC().Format("20060102T150405Z"))
+		if e.AllDay {
+			out.line("DTSTART;VALUE=DATE", e.Start.Format("20060102"))
+			out.line("DTEND;VALUE=DATE", e.Start.AddDate(0, 0, 1).Format("20060102"))
+		} else {
+			out.line("DTSTART", e.Start.UTC().Format("20060102T150405Z"))
+		}
+		out.line("SUMMARY", textEscaper.Replace(e.Summary))
+		if e.Description != "" {
+			out.line("DESCRIPTION", textEscaper.Replace(e.Description))
+		}
+		out.line("END", "VEVENT")
+	}
+	out.line("END", "VCALENDAR")
+
+	if out.err != nil {
+		return out.err
+	}
+	return out.w.Flush()
+}
+
+// UID returns a UID for an event that is the same every time it is made from
+// the same parts.
+func UID(parts ...string) string {
+	return fmt.Sprintf("%x@logbook", sha1.Sum([]byte(strings.Join(parts, "\x00"))))
+}
@@ -0,0 +1,92 @@
+package ics
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestWrite(t *testing.T) {
+	stamp := time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+	events := []*Event{
+		{
+			UID:         "1@logbook",
+			Summary:     "Buy milk, eggs; and a \\ for the baker",
+			Description: "Line one\nLine two",
+			Start:       time.Date(2000, time.January, 31, 0, 0, 0, 0, time.UTC),
+			AllDay:      true,
+			Stamp:       stamp,
+		},
+		{
+			UID:     "2@logbook",
+			Summary: "A reminder that is long enough that it has to be folded onto the next line",
+			Start:   time.Date(2000, time.February, 1, 9, 30, 0, 0, time.UTC),
+			Stamp:   stamp,
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, events); err != nil {
+		t.Fatal(err)
+	}
+
+	want := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//achew22//logbook//EN",
+		"CALSCALE:GREGORIAN",
+		"BEGIN:VEVENT",
+		"UID:1@logbook",
+		"DTSTAMP:20000101T000000Z",
+		"DTSTART;VALUE=DATE:20000131",
+		"DTEND;VALUE=DATE:20000201",
+		`SUMMARY:Buy milk\, eggs\; and a \\ for the baker`,
+		`DESCRIPTION:Line one\nLine two`,
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:2@logbook",
+		"DTSTAMP:20000101T000000Z",
+		"DTSTART:20000201T093000Z",
+		"SUMMARY:A reminder that is long enough that it has to be folded onto the ne",
+		" xt line",
+		"END:VEVENT",
+		"END:VCALENDAR",
+		"",
+	}, "\r\n")
+	if diff := cmp.Diff(strings.Split(buf.String(), "\r\n"), strings.Split(want, "\r\n")); diff != "" {
+		t.Errorf("Differences:\n%s", diff)
+	}
+}
+
+func TestWriteFoldsMultiByteCharacters(t *testing.T) {
+	var buf bytes.Buffer
+	err := Write(&buf, []*Event{{
+		Summary: strings.Repeat("é", 50),
+		AllDay:  true,
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, line := range strings.Split(buf.String(), "\r\n") {
+		if len(line) > maxLineLength {
+			t.Errorf("Line is %d octets long: %q", len(line), line)
+		}
+		if !utf8.ValidString(line) {
+			t.Errorf("Line was folded in the middle of a character: %q", line)
+		}
+	}
+}
+
+func TestUID(t *testing.T) {
+	if UID("a", "b") != UID("a", "b") {
+		t.Errorf("Expected UIDs made from the same parts to be the same")
+	}
+	if UID("a", "bc") == UID("ab", "c") {
+		t.Errorf("Expected UIDs made from different parts to be different")
+	}
+}
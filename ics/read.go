@@ -0,0 +1,240 @@
+/**
+ * Copyright 2018 Google LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the license.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specified language governing permissions and
+ * limitations under the License.
+ */
+package ics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// textUnescaper undoes textEscaper.
+var textUnescaper = strings.NewReplacer(
+	`\\`, `\`,
+	`\;`, ";",
+	`\,`, ",",
+	`\n`, "\n",
+	`\N`, "\n",
+)
+
+// contentLine is a single "NAME;PARAM=VALUE:value" line once it has been
+// unfolded.
+type contentLine struct {
+	number int
+	name   string
+	params map[string]string
+	value  string
+}
+
+func parseContentLine(number int, line string) (*contentLine, error) {
+	// Parameter values can be quoted, and the quotes can contain colons.
+	colon := -1
+	quoted := false
+	for i, c := range line {
+		if c == '"' {
+			quoted = !quoted
+		} else if c == ':' && !quoted {
+			colon = i
+			break
+		}
+	}
+	if colon < 0 {
+		return nil, fmt.Errorf("line %d: expected a colon in %q", number, line)
+	}
+
+	parts := strings.Split(line[:colon], ";")
+	l := &contentLine{
+		number: number,
+		name:   strings.ToUpper(parts[0]),
+		params: map[string]string{},
+		value:  line[colon+1:],
+	}
+	for _, param := range parts[1:] {
+		kv := strings.SplitN(param, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("line %d: invalid parameter %q", number, param)
+		}
+		l.params[strings.ToUpper(kv[0])] = strings.Trim(kv[1], `"`)
+	}
+	return l, nil
+}
+
+// Warnings is the error Read returns, along with the rest of the events, when
+// some of the events were skipped.
+type Warnings []string
+
+func (w Warnings) Error() string {
+	if len(w) == 1 {
+		return w[0]
+	}
+	return fmt.Sprintf("%s (and %d more)", w[0], len(w)-1)
+}
+
+// unknownZone is the error parseTime returns for a TZID that isn't in the
+// system's time zone database.
+type unknownZone struct {
+	tzid string
+}
+
+func (u *unknownZone) Error() string {
+	return fmt.Sprintf("unknown time zone %q", u.tzid)
+}
+
+// parseTime parses a DATE or DATE-TIME value. Times without a time zone are
+// in the local time zone. Times in a zone that isn't in the system's
+// database, like the ones calendars define for themselves, are returned in
+// the local time zone along with an *unknownZone error.
+func parseTime(l *contentLine) (t time.Time, allDay bool, err error) {
+	if l.params["VALUE"] == "DATE" || len(l.value) == len("20060102") {
+		t, err = time.ParseInLocation("20060102", l.value, time.Local)
+		return t, true, err
+	}
+
+	if strings.HasSuffix(l.value, "Z") {
+		t, err = time.Parse("20060102T150405Z", l.value)
+		return t, false, err
+	}
+
+	loc := time.Local
+	var zoneErr error
+	if tzid, ok := l.params["TZID"]; ok {
+		// VTIMEZONE definitions aren't read, so zones that are only defined
+		// by the calendar, like Outlook's "Pacific Standard Time", can't be
+		// used.
+		if tz, err := time.LoadLocation(tzid); err == nil {
+			loc = tz
+		} else {
+			zoneErr = &unknownZone{tzid: tzid}
+		}
+	}
+	t, err = time.ParseInLocation("20060102T150405", l.value, loc)
+	if err == nil {
+		err = zoneErr
+	}
+	return t, false, err
+}
+
+// Read reads every VEVENT in a calendar. Only the properties in Event are
+// read, so recurring events only include their first occurrence. Events that
+// start in a time zone Read doesn't know are skipped rather than guessed at,
+// and the error is Warnings.
+func Read(r io.Reader) ([]*Event, error) {
+	var lines []*contentLine
+
+	// Lines that start with whitespace are a continuation of the previous
+	// line.
+	scanner := bufio.NewScanner(r)
+	var line string
+	start := 0
+	flush := func() error {
+		if line == "" {
+			return nil
+		}
+		l, err := parseContentLine(start, line)
+		if err != nil {
+			return err
+		}
+		lines = append(lines, l)
+		return nil
+	}
+	for number := 1; scanner.Scan(); number++ {
+		text := strings.TrimRight(scanner.Text(), "\r")
+		if strings.HasPrefix(text, " ") || strings.HasPrefix(text, "\t") {
+			line += text[1:]
+			continue
+		}
+		if err := flush(); err != nil {
+			return nil, err
+		}
+		line, start = text, number
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+
+	var events []*Event
+	var warnings Warnings
+	var event *Event
+	// skipped is why the current event is skipped, if it is.
+	var skipped string
+	nested := 0
+	for _, l := range lines {
+		switch {
+		case l.name == "BEGIN" && strings.ToUpper(l.value) == "VEVENT":
+			event = &Event{}
+			skipped = ""
+			nested = 0
+		case l.name == "END" && strings.ToUpper(l.value) == "VEVENT":
+			if event == nil {
+				return nil, fmt.Errorf("line %d: END:VEVENT without BEGIN:VEVENT", l.number)
+			}
+			if skipped != "" {
+				warnings = append(warnings, fmt.Sprintf("line %d: skipped event %q since %s", l.number, event.Summary, skipped))
+				event = nil
+				continue
+			}
+			if event.Start.IsZero() {
+				return nil, fmt.Errorf("line %d: event %q doesn't have a DTSTART", l.number, event.Summary)
+			}
+			events = append(events, event)
+			event = nil
+		case event == nil:
+			// Properties of the calendar, or of other components like
+			// VTIMEZONE, aren't needed.
+		case l.name == "BEGIN":
+			// Components in events, like VALARM, have properties of their
+			// own which aren't needed either.
+			nested++
+		case l.name == "END":
+			nested--
+		case nested > 0:
+		case l.name == "UID":
+			event.UID = l.value
+		case l.name == "SUMMARY":
+			event.Summary = textUnescaper.Replace(l.value)
+		case l.name == "DESCRIPTION":
+			event.Description = textUnescaper.Replace(l.value)
+		case l.name == "DTSTART":
+			var err error
+			event.Start, event.AllDay, err = parseTime(l)
+			if u, ok := err.(*unknownZone); ok {
+				skipped = fmt.Sprintf("it starts in an %v", u)
+			} else if err != nil {
+				return nil, fmt.Errorf("line %d: invalid DTSTART: %v", l.number, err)
+			}
+		case l.name == "DTSTAMP":
+			var err error
+			event.Stamp, _, err = parseTime(l)
+			// Stamps are only used to order changes, so an unknown zone
+			// is close enough.
+			if _, ok := err.(*unknownZone); !ok && err != nil {
+				return nil, fmt.Errorf("line %d: invalid DTSTAMP: %v", l.number, err)
+			}
+		}
+	}
+	if event != nil {
+		return nil, fmt.Errorf("event %q is missing END:VEVENT", event.Summary)
+	}
+	if len(warnings) > 0 {
+		return events, warnings
+	}
+	return events, nil
+}
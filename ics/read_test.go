@@ -0,0 +1,135 @@
+package ics
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRead(t *testing.T) {
+	calendar := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//Example//Calendar//EN",
+		"BEGIN:VEVENT",
+		"UID:standup@example.com",
+		"DTSTAMP:20000101T000000Z",
+		"DTSTART;TZID=America/New_York:20000103T093000",
+		`SUMMARY:Standup\, with the \; whole team`,
+		"DESCRIPTION:A description that was long enough to be folded onto",
+		"  a second line",
+		"BEGIN:VALARM",
+		"DESCRIPTION:Reminder",
+		"END:VALARM",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:holiday@example.com",
+		"DTSTART;VALUE=DATE:20000117",
+		"SUMMARY:Holiday",
+		"END:VEVENT",
+		"END:VCALENDAR",
+		"",
+	}, "\r\n")
+
+	got, err := Read(strings.NewReader(calendar))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []*Event{
+		{
+			UID:         "standup@example.com",
+			Summary:     "Standup, with the ; whole team",
+			Description: "A description that was long enough to be folded onto a second line",
+			Start:       time.Date(2000, time.January, 3, 9, 30, 0, 0, newYork),
+			Stamp:       time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			UID:     "holiday@example.com",
+			Summary: "Holiday",
+			Start:   time.Date(2000, time.January, 17, 0, 0, 0, 0, time.Local),
+			AllDay:  true,
+		},
+	}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("Differences:\n%s", diff)
+	}
+}
+
+func TestReadUnknownTimeZone(t *testing.T) {
+	calendar := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"BEGIN:VTIMEZONE",
+		"TZID:Pacific Standard Time",
+		"END:VTIMEZONE",
+		"BEGIN:VEVENT",
+		"DTSTAMP;TZID=Pacific Standard Time:20000101T000000",
+		"DTSTART;TZID=Pacific Standard Time:20000103T230000",
+		"SUMMARY:Late call",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"DTSTART;VALUE=DATE:20000117",
+		"SUMMARY:Holiday",
+		"END:VEVENT",
+		"END:VCALENDAR",
+		"",
+	}, "\r\n")
+
+	got, err := Read(strings.NewReader(calendar))
+	want := Warnings{`line 9: skipped event "Late call" since it starts in an unknown time zone "Pacific Standard Time"`}
+	if diff := cmp.Diff(err, want); diff != "" {
+		t.Errorf("Differences in the error:\n%s", diff)
+	}
+	if len(got) != 1 || got[0].Summary != "Holiday" {
+		t.Errorf("Expected only the holiday to be read, got %+v", got)
+	}
+}
+
+func TestReadWhatWasWritten(t *testing.T) {
+	want := []*Event{{
+		UID:         "1@logbook",
+		Summary:     "A summary, with; every \\ special character that is long enough to be folded",
+		Description: "Line one\nLine two",
+		Start:       time.Date(2000, time.January, 31, 0, 0, 0, 0, time.Local),
+		AllDay:      true,
+		Stamp:       time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC),
+	}}
+
+	var buf bytes.Buffer
+	if err := Write(&buf, want); err != nil {
+		t.Fatal(err)
+	}
+	got, err := Read(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("Differences:\n%s", diff)
+	}
+}
+
+func TestReadErrors(t *testing.T) {
+	tests := map[string]string{
+		"no colon":       "BEGIN:VCALENDAR\nBEGIN:VEVENT\nSUMMARY\nEND:VEVENT\n",
+		"no start":       "BEGIN:VEVENT\nSUMMARY:Lunch\nEND:VEVENT\n",
+		"invalid start":  "BEGIN:VEVENT\nDTSTART:tomorrow\nEND:VEVENT\n",
+		"unmatched end":  "END:VEVENT\n",
+		"unfinished":     "BEGIN:VEVENT\nDTSTART:20000101\n",
+		"bad parameters": "BEGIN:VEVENT\nDTSTART;VALUE:20000101\nEND:VEVENT\n",
+	}
+
+	for name, calendar := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got, err := Read(strings.NewReader(calendar)); err == nil {
+				t.Errorf("Expected an error, got %v", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,225 @@
+/**
+ * Copyright 2018 Google LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the license.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specified language governing permissions and
+ * limitations under the License.
+ */
+package parser
+
+import (
+	"crypto/sha1"
+	"encoding/gob"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/achew22/logbook/config"
+)
+
+// cacheVersion changes whenever what is cached, or how files are parsed,
+// changes so that old caches are thrown away.
+const cacheVersion = 3
+
+// cache is the results of parsing every file in a logbook.
+type cache struct {
+	// Config is a fingerprint of the settings that the results depend on.
+	Config string
+	Files  map[string]*cachedFile
+
+	// changed is true if the cache needs to be saved.
+	changed bool
+	// seen is the files found by the last parse.
+	seen map[string]bool
+}
+
+// cachedFile is the result of parsing a file, along with what is used to tell
+// whether the file has changed since.
+type cachedFile struct {
+	Size    int64
+	ModTime time.Time
+	Hash    string
+	Result  result
+}
+
+// DefaultCachePath returns where the cache for the logbook in c is kept, in
+// the user's cache directory.
+func DefaultCachePath(c *config.Config) (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	sum := sha1.Sum([]byte(c.LogPath))
+	return filepath.Join(dir, "logbook", hex.EncodeToString(sum[:8])+".gob"), nil
+}
+
+// UseCache caches the results of parsing each file at path, so that only the
+// files which have changed are parsed again.
+func (p *Parser) UseCache(path string) {
+	p.cachePath = path
+	p.cache = nil
+}
+
+// RebuildCache parses every file in the logbook, replacing anything in the
+// cache.
+func (p *Parser) RebuildCache() error {
+	p.cache = p.newCache()
+	if _, err := p.parse(); err != nil {
+		return err
+	}
+	return p.saveCache()
+}
+
+// configFingerprint identifies the settings that change how files are
+// parsed. That includes the local time zone, since calendar events are put on
+// the day they start locally.
+func (p *Parser) configFingerprint() string {
+	zone, offset := time.Now().Zone()
+	b, _ := json.Marshal(&struct {
+		Version            int
+		LogPath            string
+		FileFormat         string
+		WorkingDays        []time.Weekday
+		SkipNonWorkingDays bool
+		Ignore             config.IgnoreRules
+		Location           string
+		Zone               string
+		Offset             int
+	}{
+		Version:            cacheVersion,
+		LogPath:            p.config.LogPath,
+		FileFormat:         p.fileFormat(),
+		WorkingDays:        p.config.WorkingDays,
+		SkipNonWorkingDays: p.config.SkipNonWorkingDays,
+		Ignore:             p.config.Ignore,
+		Location:           time.Local.String(),
+		Zone:               zone,
+		Offset:             offset,
+	})
+	sum := sha1.Sum(b)
+	return hex.EncodeToString(sum[:])
+}
+
+func (p *Parser) newCache() *cache {
+	return &cache{
+		Config:  p.configFingerprint(),
+		Files:   map[string]*cachedFile{},
+		changed: true,
+	}
+}
+
+// loadCache reads the cache if it hasn't been already, and gets it ready to
+// track which files are seen.
+func (p *Parser) loadCache() {
+	if p.cachePath == "" {
+		return
+	}
+	if p.cache == nil {
+		p.cache = p.readCache()
+	}
+	p.cache.seen = map[string]bool{}
+}
+
+// readCache reads the cache at cachePath. A cache that can't be read, or is
+// for different settings, is started over.
+func (p *Parser) readCache() *cache {
+	f, err := os.Open(p.cachePath)
+	if err != nil {
+		return p.newCache()
+	}
+	defer f.Close()
+
+	var c cache
+	if err := gob.NewDecoder(f).Decode(&c); err != nil || c.Config != p.configFingerprint() {
+		return p.newCache()
+	}
+	return &c
+}
+
+// saveCache writes the cache if anything in it has changed.
+func (p *Parser) saveCache() error {
+	if p.cachePath == "" || p.cache == nil {
+		return nil
+	}
+
+	// Files that weren't seen while parsing have been removed.
+	for path := range p.cache.Files {
+		if !p.cache.seen[path] {
+			delete(p.cache.Files, path)
+			p.cache.changed = true
+		}
+	}
+	if !p.cache.changed {
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(p.cachePath), 0700); err != nil {
+		return err
+	}
+
+	// Write to a temporary file first so that the cache is never left half
+	// written.
+	tmp, err := ioutil.TempFile(filepath.Dir(p.cachePath), filepath.Base(p.cachePath))
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := gob.NewEncoder(tmp).Encode(p.cache); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), p.cachePath); err != nil {
+		return err
+	}
+
+	p.cache.changed = false
+	return nil
+}
+
+// fromCache sets f.result from the cache, returning true, if f hasn't changed
+// since it was cached.
+func (p *Parser) fromCache(f *file) bool {
+	if p.cache == nil {
+		return false
+	}
+
+	p.cache.seen[f.path] = true
+	f.cached = p.cache.Files[f.path]
+	if f.cached != nil && f.cached.Size == f.info.Size() && f.cached.ModTime.Equal(f.info.ModTime()) {
+		f.result = f.cached.Result
+		return true
+	}
+	return false
+}
+
+// toCache caches what was read from f.
+func (p *Parser) toCache(f *file) {
+	// Files with errors, even ones that were partly parsed, are read again
+	// every time so that the errors are too.
+	if p.cache == nil || f.err != nil {
+		return
+	}
+
+	p.cache.Files[f.path] = &cachedFile{
+		Size:    f.info.Size(),
+		ModTime: f.info.ModTime(),
+		Hash:    f.hash,
+		Result:  f.result,
+	}
+	p.cache.changed = true
+}
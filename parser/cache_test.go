@@ -0,0 +1,306 @@
+package parser
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/achew22/logbook/config"
+)
+
+func tempDir(t testing.TB) (string, func()) {
+	dir, err := ioutil.TempDir("", "logbook")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dir, func() {
+		os.RemoveAll(dir)
+	}
+}
+
+func write(t testing.TB, path, contents string) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(path, []byte(contents), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func mustJSON(t testing.TB, entries map[Date]*LogEntry) string {
+	byYmd := map[string]*LogEntry{}
+	for d, entry := range entries {
+		byYmd[d.ToYmd()] = entry
+	}
+	b, err := json.MarshalIndent(byYmd, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+func newCachedParser(c *config.Config, cachePath string) *Parser {
+	p := New(c)
+	p.UseCache(cachePath)
+	return p
+}
+
+func TestCacheMatchesParse(t *testing.T) {
+	cacheDir, cleanup := tempDir(t)
+	defer cleanup()
+
+	files, err := ioutil.ReadDir("./testdata")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, f := range files {
+		if !f.IsDir() {
+			continue
+		}
+		t.Run(f.Name(), func(t *testing.T) {
+			c := &config.Config{
+				Name:    "Demo person",
+				LogPath: filepath.Join("testdata", f.Name()),
+			}
+			cachePath := filepath.Join(cacheDir, f.Name()+".gob")
+
+			want := mustJSON(t, mustParse(t, New(c)))
+			if got := mustJSON(t, mustParse(t, newCachedParser(c, cachePath))); got != want {
+				t.Errorf("Parsing without a cache:\n%s\nParsing with an empty cache:\n%s", want, got)
+			}
+			if got := mustJSON(t, mustParse(t, newCachedParser(c, cachePath))); got != want {
+				t.Errorf("Parsing without a cache:\n%s\nParsing with a full cache:\n%s", want, got)
+			}
+		})
+	}
+}
+
+func TestCache(t *testing.T) {
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+
+	c := &config.Config{LogPath: filepath.Join(dir, "logbook")}
+	cachePath := filepath.Join(dir, "cache", "logbook.gob")
+	entryPath := filepath.Join(c.LogPath, "2000-01-01.md")
+	tomorrow := mustYmdToDate("2000-01-02")
+	reminders := func(entries map[Date]*LogEntry) []string {
+		if entry, ok := entries[tomorrow]; ok {
+			return texts(entry.PastReferences[mustYmdToDate("2000-01-01")])
+		}
+		return nil
+	}
+
+	write(t, entryPath, "tomorrow: Aaa\n")
+	if got := reminders(mustParse(t, newCachedParser(c, cachePath))); !cmp.Equal(got, []string{"Aaa"}) {
+		t.Fatalf("Got %q", got)
+	}
+	if _, err := os.Stat(cachePath); err != nil {
+		t.Fatalf("Expected the cache to be written: %v", err)
+	}
+
+	// A file with the same size and modification time isn't read again.
+	info, err := os.Stat(entryPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	write(t, entryPath, "tomorrow: Bbb\n")
+	if err := os.Chtimes(entryPath, info.ModTime(), info.ModTime()); err != nil {
+		t.Fatal(err)
+	}
+	if got := reminders(mustParse(t, newCachedParser(c, cachePath))); !cmp.Equal(got, []string{"Aaa"}) {
+		t.Errorf("Expected the cached result, got %q", got)
+	}
+
+	// Once it has been modified, it is.
+	later := info.ModTime().Add(time.Minute)
+	if err := os.Chtimes(entryPath, later, later); err != nil {
+		t.Fatal(err)
+	}
+	p := newCachedParser(c, cachePath)
+	if got := reminders(mustParse(t, p)); !cmp.Equal(got, []string{"Bbb"}) {
+		t.Errorf("Expected the file to be parsed again, got %q", got)
+	}
+
+	// Touching a file without changing it only updates the cache.
+	muchLater := later.Add(time.Minute)
+	if err := os.Chtimes(entryPath, muchLater, muchLater); err != nil {
+		t.Fatal(err)
+	}
+	if got := reminders(mustParse(t, p)); !cmp.Equal(got, []string{"Bbb"}) {
+		t.Errorf("Got %q", got)
+	}
+	if got := p.cache.Files[entryPath].ModTime; !got.Equal(muchLater) {
+		t.Errorf("Got a modification time of %v, want %v", got, muchLater)
+	}
+
+	// Removed files are removed from the cache.
+	if err := os.Remove(entryPath); err != nil {
+		t.Fatal(err)
+	}
+	if got := reminders(mustParse(t, p)); got != nil {
+		t.Errorf("Expected no reminders, got %q", got)
+	}
+	if _, ok := p.cache.Files[entryPath]; ok {
+		t.Errorf("Expected %s to be removed from the cache", entryPath)
+	}
+}
+
+func TestCacheConfigChange(t *testing.T) {
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+
+	c := &config.Config{LogPath: filepath.Join(dir, "logbook")}
+	cachePath := filepath.Join(dir, "logbook.gob")
+	write(t, filepath.Join(c.LogPath, "2000-01-07.md"), "tomorrow: Weekend plans\n")
+
+	if entries := mustParse(t, newCachedParser(c, cachePath)); entries[mustYmdToDate("2000-01-08")] == nil {
+		t.Fatalf("Expected a reminder on 2000-01-08")
+	}
+
+	// Results that depend on the config aren't reused once it changes.
+	c.SkipNonWorkingDays = true
+	entries := mustParse(t, newCachedParser(c, cachePath))
+	if entries[mustYmdToDate("2000-01-08")] != nil || entries[mustYmdToDate("2000-01-10")] == nil {
+		t.Errorf("Expected the reminder to move to 2000-01-10:\n%s", mustJSON(t, entries))
+	}
+}
+
+func TestCacheTimeZoneChange(t *testing.T) {
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+
+	local := time.Local
+	defer func() {
+		time.Local = local
+	}()
+
+	c := &config.Config{LogPath: filepath.Join(dir, "logbook")}
+	cachePath := filepath.Join(dir, "logbook.gob")
+	write(t, filepath.Join(c.LogPath, "calendars", "work.ics"), `BEGIN:VCALENDAR
+BEGIN:VEVENT
+DTSTART:20000103T230000Z
+SUMMARY:Late call
+END:VEVENT
+END:VCALENDAR
+`)
+
+	time.Local = time.UTC
+	if entries := mustParse(t, newCachedParser(c, cachePath)); entries[mustYmdToDate("2000-01-03")] == nil {
+		t.Fatalf("Expected the event on 2000-01-03")
+	}
+
+	// Events are cached on the day they start locally, so moving east moves
+	// them to the next day.
+	time.Local = time.FixedZone("UTC+2", 2*60*60)
+	entries := mustParse(t, newCachedParser(c, cachePath))
+	if entries[mustYmdToDate("2000-01-03")] != nil || entries[mustYmdToDate("2000-01-04")] == nil {
+		t.Errorf("Expected the event to move to 2000-01-04:\n%s", mustJSON(t, entries))
+	}
+}
+
+func TestInvalidCache(t *testing.T) {
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+
+	c := &config.Config{LogPath: filepath.Join(dir, "logbook")}
+	cachePath := filepath.Join(dir, "logbook.gob")
+	write(t, filepath.Join(c.LogPath, "2000-01-01.md"), "tomorrow: Aaa\n")
+	write(t, cachePath, "Not a cache")
+
+	want := mustJSON(t, mustParse(t, New(c)))
+	if got := mustJSON(t, mustParse(t, newCachedParser(c, cachePath))); got != want {
+		t.Errorf("Got:\n%s\nWant:\n%s", got, want)
+	}
+
+	b, err := ioutil.ReadFile(cachePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) == "Not a cache" {
+		t.Errorf("Expected the cache to be replaced")
+	}
+}
+
+func TestRebuildCache(t *testing.T) {
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+
+	c := &config.Config{LogPath: filepath.Join(dir, "logbook")}
+	cachePath := filepath.Join(dir, "logbook.gob")
+	entryPath := filepath.Join(c.LogPath, "2000-01-01.md")
+	write(t, entryPath, "tomorrow: Aaa\n")
+	mustParse(t, newCachedParser(c, cachePath))
+
+	// Rebuilding picks up changes the cache can't see.
+	info, err := os.Stat(entryPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	write(t, entryPath, "tomorrow: Bbb\n")
+	if err := os.Chtimes(entryPath, info.ModTime(), info.ModTime()); err != nil {
+		t.Fatal(err)
+	}
+	if err := newCachedParser(c, cachePath).RebuildCache(); err != nil {
+		t.Fatal(err)
+	}
+
+	entries := mustParse(t, newCachedParser(c, cachePath))
+	if got := texts(entries[mustYmdToDate("2000-01-02")].PastReferences[mustYmdToDate("2000-01-01")]); !cmp.Equal(got, []string{"Bbb"}) {
+		t.Errorf("Got %q", got)
+	}
+}
+
+// writeLogbook writes an entry for every working day in years of logbook.
+func writeLogbook(b *testing.B, dir string, years int) {
+	start := mustYmdToDate("2000-01-03")
+	for d := start; d.Year < start.Year+years; d = TimeToDate(d.ToTime().AddDate(0, 0, 1)) {
+		if w := d.ToTime().Weekday(); w == time.Saturday || w == time.Sunday {
+			continue
+		}
+		var entry strings.Builder
+		fmt.Fprintf(&entry, "# Demo person - %s\n\n", d.ToYmd())
+		fmt.Fprintf(&entry, "<!-- logbook:begin -->\n\n## Open items:\n\n * [ ] Item from %s\n\n<!-- logbook:end -->\n\n", d.ToYmd())
+		for i := 0; i < 5; i++ {
+			fmt.Fprintf(&entry, "Worked on part %d of the project. Lots of *very* interesting details here.\n\n", i)
+		}
+		fmt.Fprintf(&entry, " *  [ ] Follow up on %s\n *  [x] Finished\n\n", d.ToYmd())
+		fmt.Fprintf(&entry, "tomorrow: Check the build\n\nin 2 weeks: Review %s\n\nTODO: Write it down\n\nperf: Did a thing\n", d.ToYmd())
+		write(b, filepath.Join(dir, d.ToYmd()+".md"), entry.String())
+	}
+}
+
+func BenchmarkParse(b *testing.B) {
+	dir, err := ioutil.TempDir("", "logbook")
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	c := &config.Config{LogPath: filepath.Join(dir, "logbook")}
+	writeLogbook(b, c.LogPath, 5)
+
+	b.Run("NoCache", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			mustParse(b, New(c))
+		}
+	})
+
+	b.Run("Cache", func(b *testing.B) {
+		cachePath := filepath.Join(dir, "logbook.gob")
+		if err := newCachedParser(c, cachePath).RebuildCache(); err != nil {
+			b.Fatal(err)
+		}
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			mustParse(b, newCachedParser(c, cachePath))
+		}
+	})
+}
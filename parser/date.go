@@ -18,6 +18,7 @@ package parser
 import (
 	"fmt"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -33,6 +34,20 @@ func (d Date) Equals(o Date) bool {
 	return d.Year == o.Year && d.Month == o.Month && d.Day == o.Day
 }
 
+func (d Date) Before(o Date) bool {
+	return d.ToTime().Before(o.ToTime())
+}
+
+func (d Date) After(o Date) bool {
+	return d.ToTime().After(o.ToTime())
+}
+
+// DaysUntil returns the number of days from d to o, which is negative if o is
+// before d.
+func (d Date) DaysUntil(o Date) int {
+	return int(o.ToTime().Sub(d.ToTime()).Hours() / 24)
+}
+
 func (d Date) ToTime() time.Time {
 	return time.Date(d.Year, d.Month, d.Day, 0, 0, 0, 0, time.UTC)
 }
@@ -68,12 +83,146 @@ func TimeToDate(t time.Time) Date {
 	}
 }
 
-var timespecMatchers = map[*regexp.Regexp]func(Date, []string) (Date, error){
+// Quarter is a calendar quarter. Q is numbered 1 through 4.
+type Quarter struct {
+	Year int
+	Q    int
+}
+
+var quarterFinder = regexp.MustCompile(`^(\d{4})[qQ]([1-4])$`)
+
+// ParseQuarter parses a quarter in the form "2006Q1".
+func ParseQuarter(s string) (Quarter, error) {
+	r := quarterFinder.FindStringSubmatch(s)
+	if r == nil {
+		return Quarter{}, fmt.Errorf("invalid quarter %q, expected the form 2006Q1", s)
+	}
+	year, _ := strconv.Atoi(r[1])
+	q, _ := strconv.Atoi(r[2])
+	return Quarter{Year: year, Q: q}, nil
+}
+
+func QuarterOf(d Date) Quarter {
+	return Quarter{
+		Year: d.Year,
+		Q:    (int(d.Month)-1)/3 + 1,
+	}
+}
+
+func (q Quarter) String() string {
+	return fmt.Sprintf("%dQ%d", q.Year, q.Q)
+}
+
+// Start is the first day of the quarter.
+func (q Quarter) Start() Date {
+	return Date{Year: q.Year, Month: time.Month((q.Q-1)*3 + 1), Day: 1}
+}
+
+// End is the last day of the quarter.
+func (q Quarter) End() Date {
+	return TimeToDate(q.Start().ToTime().AddDate(0, 3, -1))
+}
+
+func (q Quarter) Contains(d Date) bool {
+	return QuarterOf(d) == q
+}
+
+// WorkWeek is the set of days of the week that are working days, indexed by
+// time.Weekday.
+type WorkWeek [7]bool
+
+var DefaultWorkWeek = WorkWeek{
+	time.Monday:    true,
+	time.Tuesday:   true,
+	time.Wednesday: true,
+	time.Thursday:  true,
+	time.Friday:    true,
+}
+
+// NewWorkWeek makes a WorkWeek out of the given days. If there are none, the
+// DefaultWorkWeek is used.
+func NewWorkWeek(days []time.Weekday) WorkWeek {
+	if len(days) == 0 {
+		return DefaultWorkWeek
+	}
+	var w WorkWeek
+	for _, day := range days {
+		w[day] = true
+	}
+	return w
+}
+
+func (w WorkWeek) IsWorkingDay(d Date) bool {
+	// A week without any working days would never finish counting, so treat
+	// it as the default.
+	if w == (WorkWeek{}) {
+		w = DefaultWorkWeek
+	}
+	return w[d.ToTime().Weekday()]
+}
+
+// NextWorkingDay returns d if it is a working day, otherwise the first working
+// day after it.
+func (w WorkWeek) NextWorkingDay(d Date) Date {
+	for !w.IsWorkingDay(d) {
+		d = TimeToDate(d.ToTime().AddDate(0, 0, 1))
+	}
+	return d
+}
+
+// AddWorkingDays returns the date n working days after d.
+func (w WorkWeek) AddWorkingDays(d Date, n int) Date {
+	for n > 0 {
+		d = TimeToDate(d.ToTime().AddDate(0, 0, 1))
+		if w.IsWorkingDay(d) {
+			n--
+		}
+	}
+	return d
+}
+
+var weekdayNames = map[string]time.Weekday{
+	"monday":    time.Monday,
+	"mon":       time.Monday,
+	"tuesday":   time.Tuesday,
+	"tues":      time.Tuesday,
+	"tue":       time.Tuesday,
+	"wednesday": time.Wednesday,
+	"wed":       time.Wednesday,
+	"thursday":  time.Thursday,
+	"thurs":     time.Thursday,
+	"thur":      time.Thursday,
+	"thu":       time.Thursday,
+	"friday":    time.Friday,
+	"fri":       time.Friday,
+	"saturday":  time.Saturday,
+	"sat":       time.Saturday,
+	"sunday":    time.Sunday,
+	"sun":       time.Sunday,
+}
+
+// weekdayRegexp compiles format with every weekday name substituted in for %s
+// as an alternation.
+func weekdayRegexp(format string) *regexp.Regexp {
+	var names []string
+	for n := range weekdayNames {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return regexp.MustCompile(fmt.Sprintf(format, strings.Join(names, "|")))
+}
+
+// daysSinceMonday is how far into the week w is. Weeks start on Monday.
+func daysSinceMonday(w time.Weekday) int {
+	return (int(w) + 6) % 7
+}
+
+var timespecMatchers = map[*regexp.Regexp]func(Date, WorkWeek, []string) (Date, error){
 	// in X days
 	// in X weeks
 	// in X months
 	// in X years
-	regexp.MustCompile("(in )?(\\d+) (day|week|month|year)s?"): func(d Date, matches []string) (Date, error) {
+	regexp.MustCompile("(in )?(\\d+) (day|week|month|year)s?"): func(d Date, w WorkWeek, matches []string) (Date, error) {
 		count, err := strconv.Atoi(matches[2])
 		if err != nil {
 			return d, fmt.Errorf("Unable to convert %q to int: %v", matches[2], err)
@@ -94,13 +243,57 @@ var timespecMatchers = map[*regexp.Regexp]func(Date, []string) (Date, error){
 		return TimeToDate(d.ToTime().AddDate(years, months, days)), nil
 	},
 
+	// in X business days
+	// in X workdays
+	// in X working days
+	regexp.MustCompile("^(in )?(\\d+) (business days?|workdays?|working days?)$"): func(d Date, w WorkWeek, matches []string) (Date, error) {
+		count, err := strconv.Atoi(matches[2])
+		if err != nil {
+			return d, fmt.Errorf("Unable to convert %q to int: %v", matches[2], err)
+		}
+		return w.AddWorkingDays(d, count), nil
+	},
+
 	// tomorrow
-	regexp.MustCompile("tomorrow"): func(d Date, matches []string) (Date, error) {
+	regexp.MustCompile("tomorrow"): func(d Date, w WorkWeek, matches []string) (Date, error) {
 		return TimeToDate(d.ToTime().AddDate(0, 0, 1)), nil
 	},
+
+	// monday
+	// tue
+	// The next one after the current date, which is up to a week away.
+	weekdayRegexp("^(%s)$"): func(d Date, w WorkWeek, matches []string) (Date, error) {
+		days := (int(weekdayNames[matches[1]])-int(d.ToTime().Weekday())+6)%7 + 1
+		return TimeToDate(d.ToTime().AddDate(0, 0, days)), nil
+	},
+
+	// next monday
+	// next tue
+	// The one in the week after the current date's week.
+	weekdayRegexp("^next (%s)$"): func(d Date, w WorkWeek, matches []string) (Date, error) {
+		days := 7 - daysSinceMonday(d.ToTime().Weekday()) + daysSinceMonday(weekdayNames[matches[1]])
+		return TimeToDate(d.ToTime().AddDate(0, 0, days)), nil
+	},
+
+	// this monday
+	// this tue
+	// The one in the current date's week, which must not have passed yet.
+	weekdayRegexp("^this (%s)$"): func(d Date, w WorkWeek, matches []string) (Date, error) {
+		days := daysSinceMonday(weekdayNames[matches[1]]) - daysSinceMonday(d.ToTime().Weekday())
+		if days <= 0 {
+			return d, fmt.Errorf("%q is not after %s", matches[0], d.ToYmd())
+		}
+		return TimeToDate(d.ToTime().AddDate(0, 0, days)), nil
+	},
 }
 
+// ParseTimespec resolves spec relative to d, counting business days with the
+// DefaultWorkWeek.
 func ParseTimespec(d Date, spec string) (Date, error) {
+	return parseTimespec(d, DefaultWorkWeek, spec)
+}
+
+func parseTimespec(d Date, w WorkWeek, spec string) (Date, error) {
 	spec = strings.ToLower(spec)
 
 	if res, err := YmdToDate(spec); err == nil {
@@ -109,7 +302,7 @@ func ParseTimespec(d Date, spec string) (Date, error) {
 
 	for r, f := range timespecMatchers {
 		if matches := r.FindStringSubmatch(spec); matches != nil {
-			return f(d, matches)
+			return f(d, w, matches)
 		}
 	}
 
@@ -107,3 +107,149 @@ func TestParseTimespec(t *testing.T) {
 		})
 	}
 }
+
+func TestQuarter(t *testing.T) {
+	tests := map[string]struct {
+		in    string
+		start Date
+		end   Date
+	}{
+		"Q1":        {"2001Q1", mustYmdToDate("2001-01-01"), mustYmdToDate("2001-03-31")},
+		"Q2":        {"2001Q2", mustYmdToDate("2001-04-01"), mustYmdToDate("2001-06-30")},
+		"Q3":        {"2001Q3", mustYmdToDate("2001-07-01"), mustYmdToDate("2001-09-30")},
+		"Q4":        {"2001Q4", mustYmdToDate("2001-10-01"), mustYmdToDate("2001-12-31")},
+		"Lowercase": {"2001q4", mustYmdToDate("2001-10-01"), mustYmdToDate("2001-12-31")},
+	}
+	for n, test := range tests {
+		t.Run(n, func(t *testing.T) {
+			q, err := ParseQuarter(test.in)
+			if err != nil {
+				t.Fatalf("Expected no error but got [%v]", err)
+			}
+			if !q.Start().Equals(test.start) {
+				t.Errorf("Expected start to be [%v], was [%v]", test.start, q.Start())
+			}
+			if !q.End().Equals(test.end) {
+				t.Errorf("Expected end to be [%v], was [%v]", test.end, q.End())
+			}
+			if QuarterOf(test.start) != q || QuarterOf(test.end) != q {
+				t.Errorf("Expected %v and %v to be in %v", test.start, test.end, q)
+			}
+		})
+	}
+}
+
+func TestInvalidQuarter(t *testing.T) {
+	for _, in := range []string{"2001Q5", "2001Q0", "Q1", "2001-Q1"} {
+		t.Run(in, func(t *testing.T) {
+			if _, err := ParseQuarter(in); err == nil {
+				t.Errorf("Succeeded in parsing a quarter that should have failed: %s", in)
+			}
+		})
+	}
+}
+
+func TestParseTimespecWeekdays(t *testing.T) {
+	// 2001-02-03 is a Saturday, 2001-02-04 is a Sunday and 2001-02-05 is a
+	// Monday. 2000-12-29 is a Friday.
+	tests := map[string]struct {
+		now Date
+		in  string
+		d   Date
+		err error
+	}{
+		"Weekday later this week":      {mustYmdToDate("2001-02-05"), "friday", mustYmdToDate("2001-02-09"), nil},
+		"Weekday next week":            {mustYmdToDate("2001-02-07"), "monday", mustYmdToDate("2001-02-12"), nil},
+		"Same weekday is a week later": {mustYmdToDate("2001-02-05"), "monday", mustYmdToDate("2001-02-12"), nil},
+		"Weekday across week boundary": {mustYmdToDate("2001-02-04"), "monday", mustYmdToDate("2001-02-05"), nil},
+		"Weekday across year boundary": {mustYmdToDate("2000-12-29"), "tuesday", mustYmdToDate("2001-01-02"), nil},
+		"Weekday on the weekend":       {mustYmdToDate("2001-02-03"), "sunday", mustYmdToDate("2001-02-04"), nil},
+		"Abbreviated weekday":          {mustYmdToDate("2001-02-05"), "wed", mustYmdToDate("2001-02-07"), nil},
+		"Tues":                         {mustYmdToDate("2001-02-05"), "tues", mustYmdToDate("2001-02-06"), nil},
+		"Thurs":                        {mustYmdToDate("2001-02-05"), "thurs", mustYmdToDate("2001-02-08"), nil},
+		"case sensitive weekday":       {mustYmdToDate("2001-02-05"), "Friday", mustYmdToDate("2001-02-09"), nil},
+
+		"Next weekday from monday":         {mustYmdToDate("2001-02-05"), "next friday", mustYmdToDate("2001-02-16"), nil},
+		"Next same weekday":                {mustYmdToDate("2001-02-05"), "next monday", mustYmdToDate("2001-02-12"), nil},
+		"Next weekday from saturday":       {mustYmdToDate("2001-02-03"), "next tuesday", mustYmdToDate("2001-02-06"), nil},
+		"Next weekday from sunday":         {mustYmdToDate("2001-02-04"), "next monday", mustYmdToDate("2001-02-05"), nil},
+		"Next sunday from monday":          {mustYmdToDate("2001-02-05"), "next sunday", mustYmdToDate("2001-02-18"), nil},
+		"Next weekday across year":         {mustYmdToDate("2000-12-29"), "next fri", mustYmdToDate("2001-01-05"), nil},
+		"This weekday":                     {mustYmdToDate("2001-02-05"), "this thursday", mustYmdToDate("2001-02-08"), nil},
+		"This weekend":                     {mustYmdToDate("2001-02-05"), "this sun", mustYmdToDate("2001-02-11"), nil},
+		"This weekday on the last day":     {mustYmdToDate("2001-02-03"), "this sunday", mustYmdToDate("2001-02-04"), nil},
+		"This weekday across year":         {mustYmdToDate("2000-12-29"), "this sunday", mustYmdToDate("2000-12-31"), nil},
+		"Month is not a weekday":           {mustYmdToDate("2001-02-05"), "in 1 month", mustYmdToDate("2001-03-05"), nil},
+		"Misspelled weekday is not a spec": {mustYmdToDate("2001-02-05"), "fryday", mustYmdToDate("2001-02-05"), fmt.Errorf("no valid spec parser found for spec \"fryday\"")},
+
+		"This weekday that passed": {mustYmdToDate("2001-02-07"), "this monday", mustYmdToDate("2001-02-07"), fmt.Errorf("\"this monday\" is not after 2001-02-07")},
+		"This weekday is today":    {mustYmdToDate("2001-02-07"), "this wednesday", mustYmdToDate("2001-02-07"), fmt.Errorf("\"this wednesday\" is not after 2001-02-07")},
+		"Next is not a spec":       {mustYmdToDate("2001-02-07"), "next", mustYmdToDate("2001-02-07"), fmt.Errorf("no valid spec parser found for spec \"next\"")},
+	}
+	for n, test := range tests {
+		t.Run(n, func(t *testing.T) {
+			r, err := ParseTimespec(test.now, test.in)
+			if err == nil && test.err != nil {
+				t.Fatalf("Expected an error but didn't get one")
+			}
+			if err != nil && test.err == nil {
+				t.Fatalf("Expected no error but got [%v]", err)
+			}
+			if err != nil && err.Error() != test.err.Error() {
+				t.Fatalf("Expected error to be [%v], was [%v]", test.err, err)
+			}
+			if !test.d.Equals(r) {
+				t.Fatalf("Expected date to be [%v], was [%v]", test.d, r)
+			}
+		})
+	}
+}
+
+func TestParseTimespecBusinessDays(t *testing.T) {
+	// 2001-02-02 is a Friday and 2001-02-03 is a Saturday.
+	tests := map[string]struct {
+		now Date
+		in  string
+		d   Date
+	}{
+		"Within the week":           {mustYmdToDate("2001-01-29"), "in 2 business days", mustYmdToDate("2001-01-31")},
+		"Over the weekend":          {mustYmdToDate("2001-02-02"), "in 2 business days", mustYmdToDate("2001-02-06")},
+		"From the weekend":          {mustYmdToDate("2001-02-03"), "in 1 business day", mustYmdToDate("2001-02-05")},
+		"A whole week":              {mustYmdToDate("2001-02-02"), "in 5 workdays", mustYmdToDate("2001-02-09")},
+		"Workday":                   {mustYmdToDate("2001-02-02"), "in 1 workday", mustYmdToDate("2001-02-05")},
+		"Working days":              {mustYmdToDate("2001-02-02"), "3 working days", mustYmdToDate("2001-02-07")},
+		"Over the year":             {mustYmdToDate("2000-12-29"), "in 1 business day", mustYmdToDate("2001-01-01")},
+		"case sensitive":            {mustYmdToDate("2001-02-02"), "In 1 Business Day", mustYmdToDate("2001-02-05")},
+		"Calendar days still count": {mustYmdToDate("2001-02-02"), "in 2 days", mustYmdToDate("2001-02-04")},
+	}
+	for n, test := range tests {
+		t.Run(n, func(t *testing.T) {
+			r, err := ParseTimespec(test.now, test.in)
+			if err != nil {
+				t.Fatalf("Expected no error but got [%v]", err)
+			}
+			if !test.d.Equals(r) {
+				t.Fatalf("Expected date to be [%v], was [%v]", test.d, r)
+			}
+		})
+	}
+}
+
+func TestWorkWeek(t *testing.T) {
+	// Sunday through Thursday.
+	w := NewWorkWeek([]time.Weekday{time.Sunday, time.Monday, time.Tuesday, time.Wednesday, time.Thursday})
+
+	// 2001-02-01 is a Thursday.
+	if got, want := w.AddWorkingDays(mustYmdToDate("2001-02-01"), 1), mustYmdToDate("2001-02-04"); !got.Equals(want) {
+		t.Errorf("Expected AddWorkingDays to be [%v], was [%v]", want, got)
+	}
+	if got, want := w.NextWorkingDay(mustYmdToDate("2001-02-02")), mustYmdToDate("2001-02-04"); !got.Equals(want) {
+		t.Errorf("Expected NextWorkingDay to be [%v], was [%v]", want, got)
+	}
+	if got, want := w.NextWorkingDay(mustYmdToDate("2001-02-01")), mustYmdToDate("2001-02-01"); !got.Equals(want) {
+		t.Errorf("Expected NextWorkingDay of a working day to be [%v], was [%v]", want, got)
+	}
+	if got := NewWorkWeek(nil); got != DefaultWorkWeek {
+		t.Errorf("Expected an empty work week to be the default, was %v", got)
+	}
+}
@@ -0,0 +1,100 @@
+/**
+ * Copyright 2018 Google LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the license.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specified language governing permissions and
+ * limitations under the License.
+ */
+package parser
+
+import (
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/achew22/logbook/config"
+)
+
+// ignoreRules is the compiled form of config.IgnoreRules.
+type ignoreRules struct {
+	words    map[string]bool
+	prefixes []string
+	patterns []*regexp.Regexp
+}
+
+func newIgnoreRules(c config.IgnoreRules) *ignoreRules {
+	rules := []config.IgnoreRules{c}
+	if !c.SkipDefaults {
+		rules = append(rules, config.DefaultIgnoreRules)
+	}
+
+	r := &ignoreRules{
+		words: map[string]bool{},
+	}
+	for _, rule := range rules {
+		for _, w := range rule.Words {
+			r.words[strings.ToLower(w)] = true
+		}
+		for _, p := range rule.Prefixes {
+			r.prefixes = append(r.prefixes, strings.ToLower(p))
+		}
+		for _, p := range rule.Patterns {
+			// Patterns are validated when the config is loaded, so any that
+			// don't compile here were never going to match anything.
+			if re, err := regexp.Compile(p); err == nil {
+				r.patterns = append(r.patterns, re)
+			}
+		}
+	}
+	return r
+}
+
+// matches reports whether instruction should be ignored.
+func (r *ignoreRules) matches(instruction string) bool {
+	lower := strings.ToLower(trim(instruction))
+	if r.words[lower] {
+		return true
+	}
+	for _, p := range r.prefixes {
+		if strings.HasPrefix(lower, p) {
+			return true
+		}
+	}
+	for _, re := range r.patterns {
+		if re.MatchString(instruction) {
+			return true
+		}
+	}
+	return false
+}
+
+// ignoredFile reports whether the file at name matches one of the
+// config.IgnoreFiles globs.
+func (p *Parser) ignoredFile(name string) bool {
+	rel, err := filepath.Rel(p.config.LogPath, name)
+	if err != nil {
+		return false
+	}
+	rel = filepath.ToSlash(rel)
+
+	for _, glob := range p.config.IgnoreFiles {
+		if ok, _ := path.Match(glob, rel); ok {
+			return true
+		}
+		if !strings.Contains(glob, "/") {
+			if ok, _ := path.Match(glob, path.Base(rel)); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
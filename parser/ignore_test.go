@@ -0,0 +1,57 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/achew22/logbook/config"
+)
+
+func TestIgnoreRules(t *testing.T) {
+	rules := newIgnoreRules(config.IgnoreRules{
+		Words:    []string{"Re", "q"},
+		Prefixes: []string{"error"},
+		Patterns: []string{"^[a-z]+ id$"},
+	})
+
+	tests := map[string]bool{
+		// Defaults
+		"http":           true,
+		"HTTPS":          true,
+		"note":           true,
+		"//foo/bar":      true,
+		"notes":          false,
+		"tomorrow":       false,
+		"Re":             true,
+		"re":             true,
+		" Q ":            true,
+		"Requirements":   false,
+		"ERROR":          true,
+		"error 404":      true,
+		"json id":        true,
+		"JSON id":        false,
+		"json identity":  false,
+		"in 2 days":      false,
+		"every thursday": false,
+	}
+	for instruction, want := range tests {
+		t.Run(instruction, func(t *testing.T) {
+			if got := rules.matches(instruction); got != want {
+				t.Errorf("Expected matches(%q) to be %t, was %t", instruction, want, got)
+			}
+		})
+	}
+}
+
+func TestIgnoreRulesSkipDefaults(t *testing.T) {
+	rules := newIgnoreRules(config.IgnoreRules{
+		SkipDefaults: true,
+		Words:        []string{"re"},
+	})
+
+	if rules.matches("http") {
+		t.Errorf("Expected the default rules to be skipped")
+	}
+	if !rules.matches("re") {
+		t.Errorf("Expected the configured rules to still be used")
+	}
+}
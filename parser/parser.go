@@ -16,41 +16,169 @@
 package parser
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
-	"io/ioutil"
 	"os"
 	"path/filepath"
 	"regexp"
+	"runtime"
+	"sort"
 	"strings"
+	"time"
 
 	blackfriday "gopkg.in/russross/blackfriday.v2"
 
 	"github.com/achew22/logbook/config"
+	"github.com/achew22/logbook/ics"
 )
 
+// GeneratedBegin and GeneratedEnd surround the sections of an entry that are
+// generated from other entries. Only checklists are parsed between them since
+// everything else was copied from somewhere it has already been parsed.
+const (
+	GeneratedBegin = "<!-- logbook:begin -->"
+	GeneratedEnd   = "<!-- logbook:end -->"
+)
+
+// CalendarExt is the extension of iCalendar files in the logbook, whose
+// events are reminders on the day they happen.
+const CalendarExt = ".ics"
+
 var (
 	// Note that this uses the lazy + operator (+?) so that you match the
 	// smallest string. No instructions should require having a colon in
 	// it.
 	expressionFinder = regexp.MustCompile("(.+?):(.+)")
+
+	// checklistFinder matches markdown checklist items like "[ ] foo" and
+	// "[x] bar". The first group is the contents of the box.
+	checklistFinder = regexp.MustCompile(`^\s*\[([ xX])\]\s*(.*)$`)
+
+	// sinceFinder extracts the date an open item was first written from the
+	// suffix templater adds when carrying it forward.
+	sinceFinder = regexp.MustCompile(`\s*\(since (\d{4}-\d{1,2}-\d{1,2})\)$`)
+
+	// actionItemFinder matches "AI(owner)" instructions. The first group is
+	// the owner.
+	actionItemFinder = regexp.MustCompile(`(?i)^ai\((.*)\)$`)
 )
 
 type ParseError struct {
 	Message string `json:"message"`
+	Position
+}
+
+// Reminder is a remark for a later date, like "tomorrow: send the notes".
+type Reminder struct {
+	Text string `json:"text"`
+	Position
+}
+
+// String returns the text, so that templates can print the reminder as it
+// was written.
+func (r *Reminder) String() string {
+	return r.Text
+}
+
+// unknownNode starts the text of the reminders that stand in for markdown the
+// parser doesn't understand.
+const unknownNode = "Unknown node: "
+
+// Unknown reports whether r stands in for markdown the parser doesn't
+// understand, rather than being something that was written.
+func (r *Reminder) Unknown() bool {
+	return strings.HasPrefix(r.Text, unknownNode)
+}
+
+// Warning is a file in the logbook that couldn't be parsed at all, like a .md
+// file whose name isn't a date.
+type Warning struct {
+	Path    string
+	Message string
+}
+
+func (w *Warning) Error() string {
+	return fmt.Sprintf("%s: %s", w.Path, w.Message)
+}
+
+// Warnings is the error Parse returns when some of the files in the logbook
+// couldn't be parsed.
+type Warnings []*Warning
+
+func (w Warnings) Error() string {
+	if len(w) == 1 {
+		return w[0].Error()
+	}
+	return fmt.Sprintf("%v (and %d more)", w[0], len(w)-1)
+}
+
+// OpenItem is an unchecked checklist item. Since is the date the item was
+// first written, which is preserved as it is carried from day to day.
+type OpenItem struct {
+	Since Date
+	Text  string
+}
+
+func (o *OpenItem) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&struct {
+		Since string `json:"since"`
+		Text  string `json:"text"`
+	}{
+		Since: o.Since.ToYmd(),
+		Text:  o.Text,
+	})
+}
+
+// Todo is a "TODO:" remark which is added to the todo list of the next entry.
+type Todo struct {
+	Text string `json:"text"`
+}
+
+// ActionItem is an "AI(owner):" remark. An action item is done once it has
+// been checked off with "[x] AI(owner): ...", either in the entry it was
+// written in or in a later one.
+type ActionItem struct {
+	Owner string `json:"owner"`
+	Text  string `json:"text"`
+	Done  bool   `json:"done,omitempty"`
+}
+
+// PerfNote is a "perf:" remark, collected for performance review season.
+type PerfNote struct {
+	Text string `json:"text"`
 }
 
 type LogEntry struct {
 	Path string
 	Date Date
 
-	PastReferences map[Date][]string
+	// OnDisk is true if the entry was read from a file, as opposed to only
+	// being the target of a reminder.
+	OnDisk bool
+
+	PastReferences map[Date][]*Reminder
+
+	// OpenItems are the unchecked checklist items written in this entry.
+	OpenItems []*OpenItem
+
+	// Todos are the "TODO:" remarks written in this entry.
+	Todos []*Todo
+
+	// ActionItems are the "AI(owner):" remarks written in this entry.
+	ActionItems []*ActionItem
+
+	// PerfNotes are the "perf:" remarks written in this entry.
+	PerfNotes []*PerfNote
+
+	// Recurrences are the recurring reminders written in this entry.
+	Recurrences []*Recurrence
 
 	Errors []*ParseError
 }
 
-func marshalPastReferences(r map[Date][]string) map[string][]string {
-	out := map[string][]string{}
+func marshalPastReferences(r map[Date][]*Reminder) map[string][]*Reminder {
+	out := map[string][]*Reminder{}
 	for k, v := range r {
 		out[k.ToYmd()] = v
 	}
@@ -58,37 +186,208 @@ func marshalPastReferences(r map[Date][]string) map[string][]string {
 }
 func (l *LogEntry) MarshalJSON() ([]byte, error) {
 	return json.Marshal(&struct {
-		Path           string              `json:"path"`
-		Date           string              `json:"date"`
-		PastReferences map[string][]string `json:"pastReferences"`
-		Errors         []*ParseError       `json:"errors,omitempty"`
+		Path           string                 `json:"path"`
+		Date           string                 `json:"date"`
+		PastReferences map[string][]*Reminder `json:"pastReferences"`
+		OpenItems      []*OpenItem            `json:"openItems,omitempty"`
+		Todos          []*Todo                `json:"todos,omitempty"`
+		ActionItems    []*ActionItem          `json:"actionItems,omitempty"`
+		PerfNotes      []*PerfNote            `json:"perfNotes,omitempty"`
+		Recurrences    []*Recurrence          `json:"recurrences,omitempty"`
+		Errors         []*ParseError          `json:"errors,omitempty"`
 	}{
 		Path:           l.Path,
 		Date:           l.Date.ToYmd(),
 		PastReferences: marshalPastReferences(l.PastReferences),
+		OpenItems:      l.OpenItems,
+		Todos:          l.Todos,
+		ActionItems:    l.ActionItems,
+		PerfNotes:      l.PerfNotes,
+		Recurrences:    l.Recurrences,
 		Errors:         l.Errors,
 	})
 }
 
 type Parser struct {
-	config *config.Config
+	config   *config.Config
+	workWeek WorkWeek
+	ignore   *ignoreRules
+
+	// cachePath is where the cache is stored, or empty if there isn't one.
+	// cache is nil until it is read.
+	cachePath string
+	cache     *cache
+
+	// workers is how many files are parsed at once.
+	workers int
 
 	fileMap map[Date]*LogEntry
 }
 
+// result is everything found in a single file, keyed by the date it applies
+// to. A result only depends on the file and the config, which is what lets it
+// be cached.
+type result map[Date]*LogEntry
+
 func New(config *config.Config) *Parser {
 	return &Parser{
-		config: config,
+		config:   config,
+		workWeek: NewWorkWeek(config.WorkingDays),
+		ignore:   newIgnoreRules(config.Ignore),
+		workers:  runtime.GOMAXPROCS(0),
 	}
 }
 
-func (p *Parser) Parse() map[Date]*LogEntry {
+// Parse parses every file in the logbook. If some of the files couldn't be
+// parsed, everything else still is and the error is Warnings.
+func (p *Parser) Parse() (map[Date]*LogEntry, error) {
+	warnings, err := p.parse()
+	if err != nil {
+		return nil, err
+	}
+	// The cache only makes things faster, so it not being saved isn't worth
+	// failing over.
+	p.saveCache()
+
+	if len(warnings) > 0 {
+		return p.fileMap, warnings
+	}
+	return p.fileMap, nil
+}
+
+func (p *Parser) parse() (Warnings, error) {
 	p.fileMap = map[Date]*LogEntry{}
+	p.loadCache()
+
+	// First walk all the files in thie directory finding the ones that might
+	// have forward looking information.
+	var files []*file
+	var warnings Warnings
+	err := filepath.Walk(p.config.LogPath, func(path string, info os.FileInfo, err error) error {
+		f, err := p.findFile(path, info, err)
+		if w, ok := err.(*Warning); ok {
+			warnings = append(warnings, w)
+			return nil
+		}
+		if f != nil {
+			files = append(files, f)
+		}
+		return err
+	})
+	if os.IsNotExist(err) {
+		// There isn't a logbook yet.
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	// Then extract the information from all of them at once, and merge the
+	// results in order so that the output doesn't depend on which finishes
+	// first.
+	p.parseFiles(files)
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].path < files[j].path
+	})
+	for _, f := range files {
+		if f.err != nil {
+			if f.calendar {
+				// Calendars with some events skipped still have the rest.
+				warnings = append(warnings, &Warning{Path: f.path, Message: f.err.Error()})
+				if f.result != nil {
+					p.merge(f.result)
+				}
+				continue
+			}
+			// The entry is still there even though it can't be read.
+			f.result = result{}
+			entry := f.result.getOrCreateLog(f.date)
+			entry.OnDisk = true
+			entry.Path = f.path
+			f.result.emitError(f.date, Position{Path: f.path}, f.err)
+		}
+		p.merge(f.result)
+	}
 
-	// First walk all the files in thie directory extracting any forward looking information they might have.
-	filepath.Walk(p.config.LogPath, p.parseFile)
+	sort.Slice(warnings, func(i, j int) bool {
+		return warnings[i].Path < warnings[j].Path
+	})
+	return warnings, nil
+}
+
+// RemindersOn returns every reminder for d keyed by the date it was written,
+// including recurring reminders that occur on d. Reminders that stand in for
+// markdown the parser doesn't understand are left out.
+func RemindersOn(entries map[Date]*LogEntry, d Date) map[Date][]*Reminder {
+	reminders := map[Date][]*Reminder{}
+	if entry, ok := entries[d]; ok {
+		for origin, messages := range entry.PastReferences {
+			for _, m := range messages {
+				if !m.Unknown() {
+					reminders[origin] = append(reminders[origin], m)
+				}
+			}
+		}
+	}
+
+	for origin, entry := range entries {
+		for _, r := range entry.Recurrences {
+			if r.Occurs(d) {
+				reminders[origin] = append(reminders[origin], &Reminder{
+					Text:     r.Text,
+					Position: r.Position,
+				})
+			}
+		}
+	}
+	return reminders
+}
+
+// PreviousEntry returns the latest entry before d that was read from disk, or
+// nil if there isn't one.
+func PreviousEntry(entries map[Date]*LogEntry, d Date) *LogEntry {
+	var prev *LogEntry
+	for date, entry := range entries {
+		if !entry.OnDisk || !date.Before(d) {
+			continue
+		}
+		if prev == nil || date.After(prev.Date) {
+			prev = entry
+		}
+	}
+	return prev
+}
+
+func (p *Parser) fileFormat() string {
+	if p.config.FileFormat == "" {
+		return config.DefaultFileFormat
+	}
+	return p.config.FileFormat
+}
+
+// PathFor returns the path the entry for d is stored at.
+func (p *Parser) PathFor(d Date) string {
+	return filepath.Join(p.config.LogPath, filepath.FromSlash(d.ToTime().Format(p.fileFormat())))
+}
+
+// dateForPath is the inverse of PathFor.
+func (p *Parser) dateForPath(path string) (Date, error) {
+	format := p.fileFormat()
+	if format == config.DefaultFileFormat {
+		// Files named by hand don't always have leading zeros, so use the more
+		// lenient YmdToDate.
+		name := filepath.Base(path)
+		return YmdToDate(strings.TrimSuffix(name, filepath.Ext(name)))
+	}
 
-	return p.fileMap
+	rel, err := filepath.Rel(p.config.LogPath, path)
+	if err != nil {
+		return Date{}, err
+	}
+	t, err := time.Parse(format, filepath.ToSlash(rel))
+	if err != nil {
+		return Date{}, err
+	}
+	return TimeToDate(t), nil
 }
 
 // getOrCreateLog either gets from the eisting fileMap a date or creates it.
@@ -97,8 +396,8 @@ func (p *Parser) getOrCreateLog(d Date) *LogEntry {
 	if !ok {
 		p.fileMap[d] = &LogEntry{
 			Date:           d,
-			Path:           filepath.Join(p.config.LogPath, d.ToYmd()) + ".md",
-			PastReferences: map[Date][]string{},
+			Path:           p.PathFor(d),
+			PastReferences: map[Date][]*Reminder{},
 			Errors:         []*ParseError{},
 		}
 	}
@@ -106,71 +405,287 @@ func (p *Parser) getOrCreateLog(d Date) *LogEntry {
 	return p.fileMap[d]
 }
 
-func (p *Parser) emitError(d Date, err error) {
-	toLog := p.getOrCreateLog(d)
+// merge adds everything in r to the fileMap.
+func (p *Parser) merge(r result) {
+	for d, found := range r {
+		toLog := p.getOrCreateLog(d)
+		if found.OnDisk {
+			// Entries named by hand aren't always where PathFor expects.
+			toLog.OnDisk = true
+			toLog.Path = found.Path
+		}
+		for from, messages := range found.PastReferences {
+			toLog.PastReferences[from] = append(toLog.PastReferences[from], messages...)
+		}
+		toLog.OpenItems = append(toLog.OpenItems, found.OpenItems...)
+		toLog.Todos = append(toLog.Todos, found.Todos...)
+		toLog.ActionItems = append(toLog.ActionItems, found.ActionItems...)
+		toLog.PerfNotes = append(toLog.PerfNotes, found.PerfNotes...)
+		for _, r := range found.Recurrences {
+			// Which days are working days is up to the config, so it is
+			// filled in here rather than cached.
+			r := *r
+			r.skipNonWorkingDays = p.config.SkipNonWorkingDays
+			r.workWeek = p.workWeek
+			toLog.Recurrences = append(toLog.Recurrences, &r)
+		}
+		toLog.Errors = append(toLog.Errors, found.Errors...)
+	}
+}
+
+func (r result) getOrCreateLog(d Date) *LogEntry {
+	_, ok := r[d]
+	if !ok {
+		r[d] = &LogEntry{
+			Date:           d,
+			PastReferences: map[Date][]*Reminder{},
+		}
+	}
+
+	return r[d]
+}
+
+func (r result) emitError(d Date, pos Position, err error) {
+	toLog := r.getOrCreateLog(d)
 	toLog.Errors = append(toLog.Errors, &ParseError{
-		Message: err.Error(),
+		Message:  err.Error(),
+		Position: pos,
 	})
 }
 
-func (p *Parser) emitEvent(from, to Date, message string) {
-	toLog := p.getOrCreateLog(to)
-	toLog.PastReferences[from] = append(toLog.PastReferences[from], message)
+func (r result) emitEvent(from, to Date, pos Position, message string) {
+	toLog := r.getOrCreateLog(to)
+	toLog.PastReferences[from] = append(toLog.PastReferences[from], &Reminder{
+		Text:     message,
+		Position: pos,
+	})
 }
 
-func (p *Parser) parseFile(path string, info os.FileInfo, err error) error {
-	if !strings.HasSuffix(path, ".md") {
-		return nil
+func (r result) emitOpenItem(d Date, text string) {
+	item := &OpenItem{
+		Since: d,
+		Text:  text,
 	}
+	if m := sinceFinder.FindStringSubmatch(text); m != nil {
+		if since, err := YmdToDate(m[1]); err == nil {
+			item.Since = since
+			item.Text = trim(text[:len(text)-len(m[0])])
+		}
+	}
+
+	toLog := r.getOrCreateLog(d)
+	toLog.OpenItems = append(toLog.OpenItems, item)
+}
+
+func (r result) emitTodo(d Date, text string) {
+	toLog := r.getOrCreateLog(d)
+	toLog.Todos = append(toLog.Todos, &Todo{
+		Text: text,
+	})
+}
+
+func (r result) emitActionItem(d Date, owner, text string, done bool) {
+	toLog := r.getOrCreateLog(d)
+	toLog.ActionItems = append(toLog.ActionItems, &ActionItem{
+		Owner: owner,
+		Text:  text,
+		Done:  done,
+	})
+}
+
+func (r result) emitPerfNote(d Date, text string) {
+	toLog := r.getOrCreateLog(d)
+	toLog.PerfNotes = append(toLog.PerfNotes, &PerfNote{
+		Text: text,
+	})
+}
+
+func (r result) emitRecurrence(d Date, pos Position, rec *Recurrence, text string) {
+	rec.Text = text
+	rec.Position = pos
 
-	name := filepath.Base(path)
-	d, err := YmdToDate(name[:len(name)-len(".md")])
+	toLog := r.getOrCreateLog(d)
+	toLog.Recurrences = append(toLog.Recurrences, rec)
+}
+
+// findFile returns the file at path if it is one that needs parsing. Files
+// that can't be parsed are a *Warning.
+func (p *Parser) findFile(path string, info os.FileInfo, err error) (*file, error) {
+	if path == p.config.LogPath {
+		// Without the logbook directory, there's nothing to parse.
+		return nil, err
+	}
+	if p.ignoredFile(path) {
+		if info != nil && info.IsDir() {
+			return nil, filepath.SkipDir
+		}
+		return nil, nil
+	}
 	if err != nil {
-		return err
+		return nil, &Warning{Path: path, Message: err.Error()}
 	}
 
-	b, err := ioutil.ReadFile(path)
+	f := &file{
+		path:     path,
+		info:     info,
+		calendar: filepath.Ext(path) == CalendarExt,
+	}
+	if info.IsDir() || !f.calendar && !strings.HasSuffix(path, filepath.Ext(p.fileFormat())) {
+		return nil, nil
+	}
+
+	if !f.calendar {
+		f.date, err = p.dateForPath(path)
+		if err != nil {
+			return nil, p.badNameWarning(path)
+		}
+	}
+	return f, nil
+}
+
+func (p *Parser) badNameWarning(path string) *Warning {
+	return &Warning{Path: path, Message: fmt.Sprintf("skipped since the name doesn't match the file format %q", p.fileFormat())}
+}
+
+// Lint returns the parse errors in b, the contents of the file at path, which
+// doesn't need to be saved yet. Files that Parse would skip have no errors, or
+// a *Warning if Parse would warn about them.
+func (p *Parser) Lint(path string, b []byte) ([]*ParseError, error) {
+	// Parse doesn't look inside ignored directories either.
+	for dir := path; strings.HasPrefix(dir, p.config.LogPath+string(filepath.Separator)); dir = filepath.Dir(dir) {
+		if p.ignoredFile(dir) {
+			return nil, nil
+		}
+	}
+
+	if filepath.Ext(path) == CalendarExt {
+		if _, err := parseCalendar(path, b); err != nil {
+			return nil, &Warning{Path: path, Message: err.Error()}
+		}
+		return nil, nil
+	}
+	if !strings.HasSuffix(path, filepath.Ext(p.fileFormat())) {
+		return nil, nil
+	}
+
+	d, err := p.dateForPath(path)
 	if err != nil {
-		return err
+		return nil, p.badNameWarning(path)
+	}
+	return p.parseEntry(path, d, b)[d].Errors, nil
+}
+
+// parseFile parses b, the contents of f.
+func (p *Parser) parseFile(f *file, b []byte) (result, error) {
+	if f.calendar {
+		return parseCalendar(f.path, b)
 	}
+	return p.parseEntry(f.path, f.date, b), nil
+}
+
+// parseEntry parses b, the contents of the entry for d at path.
+func (p *Parser) parseEntry(path string, d Date, b []byte) result {
+	r := result{}
+	entry := r.getOrCreateLog(d)
+	entry.OnDisk = true
+	entry.Path = path
 
 	markdown := blackfriday.New()
 	rootNode := markdown.Parse(b)
-	rootNode.Walk(p.walkNodes(d))
+	rootNode.Walk(p.walkNodes(r, d, newLocator(path, b)))
 
-	return nil
+	return r
 }
 
-func (p *Parser) walkNodes(d Date) func(n *blackfriday.Node, entering bool) blackfriday.WalkStatus {
+// parseCalendar adds the events in b, the contents of the iCalendar file at
+// path, as reminders on the day they happen. Events at a particular time start
+// with the time, like "09:30 Standup". If some of the events were skipped, the
+// rest are still added and the error is ics.Warnings.
+func parseCalendar(path string, b []byte) (result, error) {
+	events, err := ics.Read(bytes.NewReader(b))
+	if _, ok := err.(ics.Warnings); !ok && err != nil {
+		return nil, err
+	}
+
+	r := result{}
+	for _, e := range events {
+		start, text := e.Start, e.Summary
+		if !e.AllDay {
+			start = start.Local()
+			text = start.Format("15:04") + " " + text
+		}
+		d := TimeToDate(start)
+		r.emitEvent(d, d, Position{Path: path}, text)
+	}
+	return r, err
+}
+
+func (p *Parser) walkNodes(r result, d Date, loc *locator) func(n *blackfriday.Node, entering bool) blackfriday.WalkStatus {
+	// generated is true between GeneratedBegin and GeneratedEnd.
+	generated := false
+
 	return func(n *blackfriday.Node, entering bool) blackfriday.WalkStatus {
 		switch n.Type {
-		case blackfriday.Document, blackfriday.Heading, blackfriday.Paragraph:
+		case blackfriday.Document, blackfriday.Heading, blackfriday.Paragraph, blackfriday.List, blackfriday.Item:
 			// These nodes can never contain any prospective information, but nodes
 			// inside of them can contain info. GoToNext recurses into those nodes.
 			return blackfriday.GoToNext
 		case blackfriday.Text:
-			p.parseEventText(d, string(n.Literal))
+			p.parseEventText(r, d, loc, string(n.Literal), generated)
+			return blackfriday.GoToNext
+		case blackfriday.HTMLBlock:
+			switch trim(string(n.Literal)) {
+			case GeneratedBegin:
+				generated = true
+			case GeneratedEnd:
+				generated = false
+			default:
+				r.emitEvent(d, d, loc.find(string(n.Literal)), fmt.Sprintf("%s%s %q", unknownNode, n, n.Literal))
+			}
 			return blackfriday.GoToNext
 		default:
-			p.emitEvent(d, d, fmt.Sprintf("Unknown node: %s %q", n, n.Literal))
+			r.emitEvent(d, d, loc.find(string(n.Literal)), fmt.Sprintf("%s%s %q", unknownNode, n, n.Literal))
 			return blackfriday.GoToNext
 		}
 	}
 }
 
-func (p *Parser) parseEventText(d Date, text string) {
+// parseEventText parses the remarks in text. If the text is in a generated
+// section, only checklist items are parsed.
+func (p *Parser) parseEventText(r result, d Date, loc *locator, text string, generated bool) {
 	type finding struct {
+		// checkbox is the contents of the box if the line was a checklist
+		// item, otherwise it is empty.
+		checkbox    string
 		instruction string
 		remark      string
+		pos         Position
 	}
 
 	var findings []finding
 	for _, line := range strings.Split(text, "\n") {
-		r := expressionFinder.FindStringSubmatch(line)
-		if len(r) >= 3 {
+		pos := loc.find(line)
+		if m := checklistFinder.FindStringSubmatch(line); m != nil {
+			f := finding{
+				checkbox: m[1],
+				remark:   m[2],
+				pos:      pos,
+			}
+			// Action items are checked off like any other checklist item.
+			if ai := expressionFinder.FindStringSubmatch(m[2]); ai != nil && actionItemFinder.MatchString(ai[1]) {
+				f.instruction = ai[1]
+				f.remark = ai[2]
+			}
+			findings = append(findings, f)
+			continue
+		}
+
+		m := expressionFinder.FindStringSubmatch(line)
+		if len(m) >= 3 {
 			findings = append(findings, finding{
-				instruction: r[1],
-				remark:      r[2],
+				instruction: m[1],
+				remark:      m[2],
+				pos:         pos,
 			})
 		} else {
 			if len(findings) > 0 {
@@ -184,46 +699,56 @@ func (p *Parser) parseEventText(d Date, text string) {
 	// mapped to a remark. Attempt to parse them using first pass
 	// heuristics.
 	for _, f := range findings {
-		instruction := strings.ToLower(f.instruction)
-		// Urls are often in my notes, ignore them.
-		if instruction == "http" || instruction == "https" {
+		if generated && f.checkbox == "" {
 			continue
 		}
 
-		// Sometimes I preface my notes with the literal "note"
-		if instruction == "note" {
+		if m := actionItemFinder.FindStringSubmatch(f.instruction); m != nil {
+			r.emitActionItem(d, trim(m[1]), trim(f.remark), f.checkbox != "" && f.checkbox != " ")
 			continue
 		}
 
-		// TODO: Parse perf notes around the end of the quarter.
-		if instruction == "perf" {
+		if f.checkbox != "" {
+			// Checked items are done, so only unchecked ones are carried
+			// forward.
+			if f.checkbox == " " {
+				r.emitOpenItem(d, trim(f.remark))
+			}
 			continue
 		}
 
-		// TODO: Parse TODO instructions into the todo list for the next day.
-		if instruction == "todo" {
+		if p.ignore.matches(f.instruction) {
 			continue
 		}
 
-		if strings.HasPrefix(instruction, "ai(") && strings.HasSuffix(instruction, ")") {
+		instruction := strings.ToLower(f.instruction)
+		if instruction == "perf" {
+			r.emitPerfNote(d, trim(f.remark))
 			continue
 		}
 
-		// TODO: Parse todo lists
-		if strings.HasPrefix(instruction, "[ ]") || strings.HasPrefix(instruction, "[x]") {
+		if instruction == "todo" {
+			r.emitTodo(d, trim(f.remark))
 			continue
 		}
 
-		// Bazel targets also get caught up in this //foo:bar. Ignore by matching "//"
-		if strings.HasPrefix(instruction, "//") {
+		if isRecurrence(f.instruction) {
+			rec, err := ParseRecurrence(d, f.instruction)
+			if err != nil {
+				r.emitError(d, f.pos, err)
+				continue
+			}
+			r.emitRecurrence(d, f.pos, rec, trim(f.remark))
 			continue
 		}
 
-		reminderDate, err := ParseTimespec(d, f.instruction)
+		reminderDate, err := parseTimespec(d, p.workWeek, f.instruction)
 		if err != nil {
-			p.emitError(d, err)
+			r.emitError(d, f.pos, err)
+		} else if p.config.SkipNonWorkingDays {
+			reminderDate = p.workWeek.NextWorkingDay(reminderDate)
 		}
 
-		p.emitEvent(d, reminderDate, trim(f.remark))
+		r.emitEvent(d, reminderDate, f.pos, trim(f.remark))
 	}
 }
@@ -22,6 +22,7 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"testing"
 
@@ -34,6 +35,23 @@ var (
 	updateGoldens = flag.Bool("update_goldens", false, "Set this to true to update the golden file that you normally compare against")
 )
 
+func mustParse(t testing.TB, p *Parser) map[Date]*LogEntry {
+	entries, err := p.Parse()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return entries
+}
+
+// texts returns the text of each reminder.
+func texts(reminders []*Reminder) []string {
+	var out []string
+	for _, r := range reminders {
+		out = append(out, r.Text)
+	}
+	return out
+}
+
 func TestParsing(t *testing.T) {
 	files, err := ioutil.ReadDir("./testdata")
 	if err != nil {
@@ -52,7 +70,7 @@ func TestParsing(t *testing.T) {
 			})
 
 			parsedOut := map[string]*LogEntry{}
-			for k, v := range p.Parse() {
+			for k, v := range mustParse(t, p) {
 				parsedOut[k.ToYmd()] = v
 			}
 
@@ -92,3 +110,297 @@ func TestParsing(t *testing.T) {
 		})
 	}
 }
+
+func TestSkipNonWorkingDays(t *testing.T) {
+	dir, err := ioutil.TempDir("", "logbook")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// 2001-02-02 is a Friday.
+	err = ioutil.WriteFile(filepath.Join(dir, "2001-02-02.md"), []byte(`# Title - 2001-02-02
+
+tomorrow: Saturday becomes Monday
+
+in 2 days: Sunday becomes Monday
+
+2001-02-06: Tuesday stays Tuesday
+`), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, skip := range []bool{false, true} {
+		entries := mustParse(t, New(&config.Config{
+			LogPath:            dir,
+			SkipNonWorkingDays: skip,
+		}))
+
+		got := map[string][]string{}
+		for d, entry := range entries {
+			for _, messages := range entry.PastReferences {
+				got[d.ToYmd()] = append(got[d.ToYmd()], texts(messages)...)
+			}
+		}
+
+		want := map[string][]string{
+			"2001-02-03": {"Saturday becomes Monday"},
+			"2001-02-04": {"Sunday becomes Monday"},
+			"2001-02-06": {"Tuesday stays Tuesday"},
+		}
+		if skip {
+			want = map[string][]string{
+				"2001-02-05": {"Saturday becomes Monday", "Sunday becomes Monday"},
+				"2001-02-06": {"Tuesday stays Tuesday"},
+			}
+		}
+		if diff := cmp.Diff(got, want); diff != "" {
+			t.Errorf("Differences with SkipNonWorkingDays=%t:\n%s", skip, diff)
+		}
+	}
+}
+
+func TestConfiguredFileFormatAndIgnoreRules(t *testing.T) {
+	dir, err := ioutil.TempDir("", "logbook")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	if err := os.MkdirAll(filepath.Join(dir, "2001"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	err = ioutil.WriteFile(filepath.Join(dir, "2001", "02-02.md"), []byte(`# Title - 2001-02-02
+
+tomorrow: Do stuff
+
+Re: the thing from yesterday
+`), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := New(&config.Config{
+		LogPath:    dir,
+		FileFormat: "2006/01-02.md",
+		Ignore: config.IgnoreRules{
+			Prefixes: []string{"RE"},
+		},
+	})
+	entries := mustParse(t, p)
+
+	today, ok := entries[mustYmdToDate("2001-02-02")]
+	if !ok || !today.OnDisk {
+		t.Fatalf("Expected an entry for 2001-02-02 read from disk, got %v", entries)
+	}
+	if len(today.Errors) > 0 {
+		t.Errorf("Expected no errors, got %v", today.Errors[0].Message)
+	}
+
+	tomorrow := entries[mustYmdToDate("2001-02-03")]
+	if got, want := tomorrow.Path, filepath.Join(dir, "2001", "02-03.md"); got != want {
+		t.Errorf("Expected path to be %q, was %q", want, got)
+	}
+	if diff := cmp.Diff(texts(tomorrow.PastReferences[mustYmdToDate("2001-02-02")]), []string{"Do stuff"}); diff != "" {
+		t.Errorf("Differences:\n%s", diff)
+	}
+}
+
+func TestWarnings(t *testing.T) {
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+
+	write(t, filepath.Join(dir, "2001-02-01.md"), "tomorrow: First\n")
+	write(t, filepath.Join(dir, "README.md"), "tomorrow: Not a reminder\n")
+	write(t, filepath.Join(dir, "notes", "ideas.md"), "tomorrow: Not a reminder\n")
+	write(t, filepath.Join(dir, "calendars", "broken.ics"), "BEGIN:VEVENT\nSUMMARY:Lunch\nEND:VEVENT\n")
+	write(t, filepath.Join(dir, "2001-02-02.md"), "tomorrow: Second\n")
+
+	entries, err := New(&config.Config{LogPath: dir}).Parse()
+	warnings, ok := err.(Warnings)
+	if !ok {
+		t.Fatalf("Expected Warnings, got %v", err)
+	}
+
+	var got []string
+	for _, w := range warnings {
+		got = append(got, w.Error())
+	}
+	want := []string{
+		filepath.Join(dir, "README.md") + `: skipped since the name doesn't match the file format "2006-01-02.md"`,
+		filepath.Join(dir, "calendars", "broken.ics") + `: line 3: event "Lunch" doesn't have a DTSTART`,
+		filepath.Join(dir, "notes", "ideas.md") + `: skipped since the name doesn't match the file format "2006-01-02.md"`,
+	}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("Differences:\n%s", diff)
+	}
+
+	// Everything else is still parsed.
+	for _, d := range []string{"2001-02-02", "2001-02-03"} {
+		if _, ok := entries[mustYmdToDate(d)]; !ok {
+			t.Errorf("Expected an entry for %s", d)
+		}
+	}
+}
+
+func TestIgnoreFiles(t *testing.T) {
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+
+	write(t, filepath.Join(dir, "2001-02-01.md"), "tomorrow: First\n")
+	write(t, filepath.Join(dir, "README.md"), "tomorrow: Not a reminder\n")
+	write(t, filepath.Join(dir, "notes", "README.md"), "tomorrow: Not a reminder\n")
+	write(t, filepath.Join(dir, "drafts", "2001-02-05.md"), "tomorrow: Not a reminder\n")
+
+	entries := mustParse(t, New(&config.Config{
+		LogPath:     dir,
+		IgnoreFiles: []string{"README.md", "drafts"},
+	}))
+	var got []string
+	for d := range entries {
+		got = append(got, d.ToYmd())
+	}
+	sort.Strings(got)
+	if diff := cmp.Diff(got, []string{"2001-02-01", "2001-02-02"}); diff != "" {
+		t.Errorf("Differences:\n%s", diff)
+	}
+}
+
+func TestUnreadableFile(t *testing.T) {
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+
+	// A link to a file that doesn't exist can't be read.
+	if err := os.Symlink(filepath.Join(dir, "missing.md"), filepath.Join(dir, "2001-02-01.md")); err != nil {
+		t.Fatal(err)
+	}
+	write(t, filepath.Join(dir, "2001-02-02.md"), "tomorrow: Second\n")
+
+	entries := mustParse(t, New(&config.Config{LogPath: dir}))
+	entry := entries[mustYmdToDate("2001-02-01")]
+	if entry == nil || !entry.OnDisk || len(entry.Errors) != 1 || !strings.Contains(entry.Errors[0].Message, "no such file or directory") {
+		t.Errorf("Expected an error reading the file, got %+v", entry)
+	}
+	if _, ok := entries[mustYmdToDate("2001-02-03")]; !ok {
+		t.Errorf("Expected the files after it to be parsed")
+	}
+}
+
+func TestMissingLogbook(t *testing.T) {
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+
+	entries, err := New(&config.Config{LogPath: filepath.Join(dir, "logbook")}).Parse()
+	if err != nil || len(entries) != 0 {
+		t.Errorf("Expected an empty logbook, got %v, %v", entries, err)
+	}
+
+	// A logbook that isn't a directory can't be parsed at all.
+	write(t, filepath.Join(dir, "logbook"), "")
+	if _, err := New(&config.Config{LogPath: filepath.Join(dir, "logbook", "x")}).Parse(); err == nil {
+		t.Errorf("Expected an error")
+	}
+}
+
+func TestLint(t *testing.T) {
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+
+	p := New(&config.Config{
+		LogPath:     dir,
+		IgnoreFiles: []string{"drafts"},
+	})
+
+	// The file doesn't need to exist.
+	path := filepath.Join(dir, "2001-02-01.md")
+	errs, err := p.Lint(path, []byte("# Title - 2001-02-01\n\ntomorrow: Fine\n\nsomeday: Not fine\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []*ParseError{{
+		Message:  `no valid spec parser found for spec "someday"`,
+		Position: Position{Path: path, Line: 5, Column: 1},
+	}}
+	if diff := cmp.Diff(errs, want); diff != "" {
+		t.Errorf("Differences:\n%s", diff)
+	}
+
+	for _, test := range []struct {
+		name     string
+		contents string
+		want     string
+	}{
+		{"README.md", "someday: Not a reminder\n", `skipped since the name doesn't match the file format "2006-01-02.md"`},
+		{"broken.ics", "BEGIN:VEVENT\nSUMMARY:Lunch\nEND:VEVENT\n", `line 3: event "Lunch" doesn't have a DTSTART`},
+		{"drafts/2001-02-02.md", "someday: Ignored\n", ""},
+		{"notes.txt", "someday: Ignored\n", ""},
+	} {
+		errs, err := p.Lint(filepath.Join(dir, test.name), []byte(test.contents))
+		if len(errs) > 0 {
+			t.Errorf("%s: expected no errors, got %v", test.name, errs[0].Message)
+		}
+		var got string
+		if w, ok := err.(*Warning); ok {
+			got = w.Message
+		} else if err != nil {
+			t.Errorf("%s: expected a Warning, got %v", test.name, err)
+		}
+		if got != test.want {
+			t.Errorf("%s: got warning %q, want %q", test.name, got, test.want)
+		}
+	}
+}
+
+func TestEntryPaths(t *testing.T) {
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+
+	// Entries named by hand are found wherever they are.
+	write(t, filepath.Join(dir, "2000-1-3.md"), "tomorrow: Aaa\n")
+	write(t, filepath.Join(dir, "sub", "2000-01-04.md"), "tomorrow: Bbb\n")
+
+	entries := mustParse(t, New(&config.Config{LogPath: dir}))
+	for ymd, want := range map[string]string{
+		"2000-01-03": filepath.Join(dir, "2000-1-3.md"),
+		"2000-01-04": filepath.Join(dir, "sub", "2000-01-04.md"),
+		// Entries that haven't been written yet go where PathFor says.
+		"2000-01-05": filepath.Join(dir, "2000-01-05.md"),
+	} {
+		if got := entries[mustYmdToDate(ymd)].Path; got != want {
+			t.Errorf("Got a path of %q for %s, want %q", got, ymd, want)
+		}
+	}
+}
+
+func TestCalendarWarnings(t *testing.T) {
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+
+	write(t, filepath.Join(dir, "calendars", "outlook.ics"), `BEGIN:VCALENDAR
+BEGIN:VEVENT
+DTSTART;TZID=Pacific Standard Time:20000103T230000
+SUMMARY:Late call
+END:VEVENT
+BEGIN:VEVENT
+DTSTART;VALUE=DATE:20000104
+SUMMARY:Holiday
+END:VEVENT
+END:VCALENDAR
+`)
+
+	entries, err := New(&config.Config{LogPath: dir}).Parse()
+	warnings, ok := err.(Warnings)
+	if !ok || len(warnings) != 1 || !strings.Contains(warnings[0].Message, `unknown time zone "Pacific Standard Time"`) {
+		t.Fatalf("Expected a warning about the time zone, got %v", err)
+	}
+
+	// The rest of the calendar is still read.
+	d := mustYmdToDate("2000-01-04")
+	if got := texts(entries[d].PastReferences[d]); !cmp.Equal(got, []string{"Holiday"}) {
+		t.Errorf("Got %q", got)
+	}
+	if _, ok := entries[mustYmdToDate("2000-01-03")]; ok {
+		t.Errorf("Expected the event in the unknown time zone to be skipped")
+	}
+}
@@ -0,0 +1,93 @@
+/**
+ * Copyright 2018 Google LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the license.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specified language governing permissions and
+ * limitations under the License.
+ */
+package parser
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"sync"
+)
+
+// file is a file in the logbook that needs parsing, along with what was found
+// in it.
+type file struct {
+	path string
+	info os.FileInfo
+
+	// date is the date of the entry, unless the file is a calendar.
+	date     Date
+	calendar bool
+
+	// cached is what the cache had for the file, if anything.
+	cached *cachedFile
+
+	hash   string
+	result result
+	err    error
+}
+
+// parseFiles fills in the result, or error, of every file. Files that aren't
+// in the cache are read and parsed by up to p.workers goroutines at once.
+func (p *Parser) parseFiles(files []*file) {
+	var toRead []*file
+	for _, f := range files {
+		if !p.fromCache(f) {
+			toRead = append(toRead, f)
+		}
+	}
+
+	work := make(chan *file)
+	var wg sync.WaitGroup
+	for i := 0; i < p.workers && i < len(toRead); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for f := range work {
+				p.readFile(f)
+			}
+		}()
+	}
+	for _, f := range toRead {
+		work <- f
+	}
+	close(work)
+	wg.Wait()
+
+	for _, f := range toRead {
+		p.toCache(f)
+	}
+}
+
+// readFile reads and parses f. It is called from many goroutines at once, so
+// it can only change f.
+func (p *Parser) readFile(f *file) {
+	b, err := ioutil.ReadFile(f.path)
+	if err != nil {
+		f.err = err
+		return
+	}
+	sum := sha1.Sum(b)
+	f.hash = hex.EncodeToString(sum[:])
+
+	if f.cached != nil && f.cached.Hash == f.hash {
+		// The file was touched without being changed.
+		f.result = f.cached.Result
+		return
+	}
+	f.result, f.err = p.parseFile(f, b)
+}
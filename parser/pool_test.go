@@ -0,0 +1,140 @@
+package parser
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/achew22/logbook/config"
+)
+
+func parseWithWorkers(t testing.TB, c *config.Config, workers int) map[Date]*LogEntry {
+	p := New(c)
+	p.workers = workers
+	return mustParse(t, p)
+}
+
+func TestParallelParseMatchesSerial(t *testing.T) {
+	files, err := ioutil.ReadDir("./testdata")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, f := range files {
+		if !f.IsDir() {
+			continue
+		}
+		t.Run(f.Name(), func(t *testing.T) {
+			c := &config.Config{
+				Name:    "Demo person",
+				LogPath: filepath.Join("testdata", f.Name()),
+			}
+
+			want := mustJSON(t, parseWithWorkers(t, c, 1))
+			if got := mustJSON(t, parseWithWorkers(t, c, 8)); got != want {
+				t.Errorf("Parsing one file at a time:\n%s\nParsing in parallel:\n%s", want, got)
+			}
+		})
+	}
+}
+
+// TestParallelMerge parses files that all add to the same entries, and checks
+// that they're merged the same way every time.
+func TestParallelMerge(t *testing.T) {
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+
+	c := &config.Config{LogPath: dir}
+	for i := 1; i <= 28; i++ {
+		d := fmt.Sprintf("2000-02-%02d", i)
+		write(t, filepath.Join(dir, d+".md"), fmt.Sprintf(`# Demo person - %s
+
+in %d days: Reminder from %s
+
+someday: Not a date
+
+ *  [ ] Open item from %s
+`, d, 30-i, d, d))
+	}
+	for i := 0; i < 10; i++ {
+		write(t, filepath.Join(dir, "calendars", fmt.Sprintf("%02d.ics", i)), fmt.Sprintf(`BEGIN:VCALENDAR
+BEGIN:VEVENT
+DTSTART;VALUE=DATE:20000301
+SUMMARY:Event %d
+END:VEVENT
+END:VCALENDAR
+`, i))
+	}
+
+	want := parseWithWorkers(t, c, 1)
+	if got := len(want[mustYmdToDate("2000-03-01")].PastReferences); got != 29 {
+		t.Fatalf("Expected reminders from 29 dates, got %d", got)
+	}
+	if got := len(want[mustYmdToDate("2000-03-01")].PastReferences[mustYmdToDate("2000-03-01")]); got != 10 {
+		t.Fatalf("Expected 10 events, got %d", got)
+	}
+
+	wantJSON := mustJSON(t, want)
+	workers := 4 * runtime.GOMAXPROCS(0)
+	for i := 0; i < 20; i++ {
+		if got := mustJSON(t, parseWithWorkers(t, c, workers)); got != wantJSON {
+			t.Fatalf("Parsing one file at a time:\n%s\nParsing in parallel:\n%s", wantJSON, got)
+		}
+	}
+}
+
+func TestParallelParseWithCache(t *testing.T) {
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+
+	c := &config.Config{LogPath: filepath.Join(dir, "logbook")}
+	for i := 1; i <= 28; i++ {
+		d := fmt.Sprintf("2000-02-%02d", i)
+		write(t, filepath.Join(c.LogPath, d+".md"), "tomorrow: Reminder from "+d+"\n")
+	}
+	want := mustJSON(t, parseWithWorkers(t, c, 1))
+
+	p := newCachedParser(c, filepath.Join(dir, "logbook.gob"))
+	p.workers = 8
+	if got := mustJSON(t, mustParse(t, p)); got != want {
+		t.Errorf("Got:\n%s\nWant:\n%s", got, want)
+	}
+
+	// Only some of the files need parsing the second time.
+	if err := os.Remove(filepath.Join(c.LogPath, "2000-02-01.md")); err != nil {
+		t.Fatal(err)
+	}
+	write(t, filepath.Join(c.LogPath, "2000-02-02.md"), "tomorrow: Changed\n")
+	entries := mustParse(t, p)
+	if _, ok := entries[mustYmdToDate("2000-02-02")].PastReferences[mustYmdToDate("2000-02-01")]; ok {
+		t.Errorf("Expected the reminder from 2000-02-01 to be gone")
+	}
+	if got := texts(entries[mustYmdToDate("2000-02-03")].PastReferences[mustYmdToDate("2000-02-02")]); len(got) != 1 || got[0] != "Changed" {
+		t.Errorf("Got %q", got)
+	}
+	if got := len(p.cache.Files); got != 27 {
+		t.Errorf("Expected 27 cached files, got %d", got)
+	}
+}
+
+func BenchmarkParseWorkers(b *testing.B) {
+	dir, err := ioutil.TempDir("", "logbook")
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	c := &config.Config{LogPath: filepath.Join(dir, "logbook")}
+	writeLogbook(b, c.LogPath, 5)
+
+	for workers := 1; workers < 2*runtime.GOMAXPROCS(0); workers *= 2 {
+		b.Run(fmt.Sprintf("Workers=%d", workers), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				parseWithWorkers(b, c, workers)
+			}
+		})
+	}
+}
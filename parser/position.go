@@ -0,0 +1,79 @@
+/**
+ * Copyright 2018 Google LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the license.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specified language governing permissions and
+ * limitations under the License.
+ */
+package parser
+
+import (
+	"strings"
+)
+
+// Position is where something was written in the logbook. Line and Column
+// start at 1, and are 0 when they aren't known.
+type Position struct {
+	Path   string `json:"path"`
+	Line   int    `json:"line,omitempty"`
+	Column int    `json:"column,omitempty"`
+}
+
+// locator finds where the text blackfriday parsed from a file is, since it
+// doesn't keep track itself. Text has to be found in the order it is written.
+type locator struct {
+	path   string
+	source string
+
+	// offset is where to start looking for the next text, which is on line.
+	offset    int
+	line      int
+	lineStart int
+}
+
+func newLocator(path string, source []byte) *locator {
+	return &locator{
+		path:   path,
+		source: string(source),
+		line:   1,
+	}
+}
+
+// find returns the position of the first line of text, or just the path if
+// it can't be found.
+func (l *locator) find(text string) Position {
+	pos := Position{Path: l.path}
+
+	if i := strings.IndexByte(text, '\n'); i >= 0 {
+		text = text[:i]
+	}
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return pos
+	}
+
+	i := strings.Index(l.source[l.offset:], text)
+	if i < 0 {
+		return pos
+	}
+	start := l.offset + i
+
+	skipped := l.source[l.offset:start]
+	if n := strings.Count(skipped, "\n"); n > 0 {
+		l.line += n
+		l.lineStart = l.offset + strings.LastIndexByte(skipped, '\n') + 1
+	}
+	l.offset = start + len(text)
+
+	pos.Line = l.line
+	pos.Column = start - l.lineStart + 1
+	return pos
+}
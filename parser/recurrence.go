@@ -0,0 +1,253 @@
+/**
+ * Copyright 2018 Google LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the license.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specified language governing permissions and
+ * limitations under the License.
+ */
+package parser
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Frequency int
+
+const (
+	Daily Frequency = iota
+	Weekly
+	Monthly
+	Yearly
+)
+
+// Recurrence is a reminder that repeats, like "every friday: send the weekly
+// status". Rather than being expanded into every date it occurs on, it is
+// checked against the dates entries are generated for.
+type Recurrence struct {
+	// Spec is the instruction the recurrence was parsed from.
+	Spec string
+	Text string
+
+	// Start is the date the recurrence was written. It occurs for the first
+	// time after Start.
+	Start Date
+
+	// Until is the last date the recurrence can occur on. The zero value
+	// means it repeats forever.
+	Until Date
+
+	Frequency Frequency
+
+	// Interval is how many days, weeks, months or years are between each
+	// occurrence.
+	Interval int
+
+	// Weekday is the day of the week a Weekly recurrence occurs on.
+	Weekday time.Weekday
+
+	// MonthDay is the day of the month a Monthly or Yearly recurrence occurs
+	// on. Months that are too short use their last day instead.
+	MonthDay int
+
+	// Position is where the recurrence was written.
+	Position
+
+	// skipNonWorkingDays moves occurrences on days that aren't working days
+	// to the next working day.
+	skipNonWorkingDays bool
+	workWeek           WorkWeek
+}
+
+func (r *Recurrence) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&struct {
+		Spec string `json:"spec"`
+		Text string `json:"text"`
+		Position
+	}{
+		Spec:     r.Spec,
+		Text:     r.Text,
+		Position: r.Position,
+	})
+}
+
+// Occurs reports whether the recurrence has an occurrence on d.
+func (r *Recurrence) Occurs(d Date) bool {
+	if !r.skipNonWorkingDays {
+		return r.occursOn(d)
+	}
+	if !r.workWeek.IsWorkingDay(d) {
+		return false
+	}
+
+	// Occurrences on the non-working days before d are moved onto it.
+	for {
+		if r.occursOn(d) {
+			return true
+		}
+		d = TimeToDate(d.ToTime().AddDate(0, 0, -1))
+		if r.workWeek.IsWorkingDay(d) {
+			return false
+		}
+	}
+}
+
+func (r *Recurrence) occursOn(d Date) bool {
+	if !d.After(r.Start) || (r.Until != Date{} && d.After(r.Until)) {
+		return false
+	}
+
+	switch r.Frequency {
+	case Daily:
+		return r.Start.DaysUntil(d)%r.Interval == 0
+	case Weekly:
+		return d.ToTime().Weekday() == r.Weekday && (r.Start.DaysUntil(d)/7)%r.Interval == 0
+	case Monthly:
+		months := (d.Year-r.Start.Year)*12 + int(d.Month) - int(r.Start.Month)
+		return months%r.Interval == 0 && d.Day == clampDay(d.Year, d.Month, r.MonthDay)
+	case Yearly:
+		return d.Month == r.Start.Month && (d.Year-r.Start.Year)%r.Interval == 0 && d.Day == clampDay(d.Year, d.Month, r.MonthDay)
+	}
+	return false
+}
+
+// clampDay returns day, or the last day of the month if it is shorter than
+// that.
+func clampDay(year int, month time.Month, day int) int {
+	last := TimeToDate(Date{Year: year, Month: month, Day: 1}.ToTime().AddDate(0, 1, -1)).Day
+	if day > last {
+		return last
+	}
+	return day
+}
+
+var (
+	// recurrenceFinder matches instructions that should be parsed as a
+	// Recurrence rather than a timespec.
+	recurrenceFinder = regexp.MustCompile(`^(every|daily|weekly|monthly|yearly|annually)\b`)
+
+	// untilFinder splits the optional end date off of a recurrence.
+	untilFinder = regexp.MustCompile(`^(.+?) until (\d{4}-\d{1,2}-\d{1,2})$`)
+)
+
+var frequencies = map[string]Frequency{
+	"day":   Daily,
+	"week":  Weekly,
+	"month": Monthly,
+	"year":  Yearly,
+}
+
+var recurrenceMatchers = map[*regexp.Regexp]func(r *Recurrence, matches []string) error{
+	// every day
+	// daily
+	regexp.MustCompile("^(every day|daily)$"): func(r *Recurrence, matches []string) error {
+		r.Frequency = Daily
+		return nil
+	},
+
+	// every week
+	// weekly
+	regexp.MustCompile("^(every week|weekly)$"): func(r *Recurrence, matches []string) error {
+		r.Frequency = Weekly
+		return nil
+	},
+
+	// every month
+	// monthly
+	regexp.MustCompile("^(every month|monthly)$"): func(r *Recurrence, matches []string) error {
+		r.Frequency = Monthly
+		return nil
+	},
+
+	// every year
+	// yearly
+	// annually
+	regexp.MustCompile("^(every year|yearly|annually)$"): func(r *Recurrence, matches []string) error {
+		r.Frequency = Yearly
+		return nil
+	},
+
+	// every 2 days
+	// every 2 weeks
+	// every 3 months
+	// every 2 years
+	regexp.MustCompile("^every (\\d+) (day|week|month|year)s?$"): func(r *Recurrence, matches []string) error {
+		interval, err := strconv.Atoi(matches[1])
+		if err != nil || interval <= 0 {
+			return fmt.Errorf("invalid interval %q in recurrence %q", matches[1], r.Spec)
+		}
+		r.Frequency = frequencies[matches[2]]
+		r.Interval = interval
+		return nil
+	},
+
+	// every monday
+	// every fri
+	weekdayRegexp("^every (%s)$"): func(r *Recurrence, matches []string) error {
+		r.Frequency = Weekly
+		r.Weekday = weekdayNames[matches[1]]
+		return nil
+	},
+
+	// monthly on the 1st
+	// every month on the 15th
+	regexp.MustCompile("^(monthly|every month) on the (\\d+)(st|nd|rd|th)?$"): func(r *Recurrence, matches []string) error {
+		day, err := strconv.Atoi(matches[2])
+		if err != nil || day < 1 || day > 31 {
+			return fmt.Errorf("invalid day of the month %q in recurrence %q", matches[2], r.Spec)
+		}
+		r.Frequency = Monthly
+		r.MonthDay = day
+		return nil
+	},
+}
+
+// isRecurrence reports whether spec should be parsed with ParseRecurrence.
+func isRecurrence(spec string) bool {
+	return recurrenceFinder.MatchString(strings.ToLower(trim(spec)))
+}
+
+// ParseRecurrence parses a recurring spec like "every monday" or "monthly on
+// the 1st until 2020-01-01" written on d.
+func ParseRecurrence(d Date, spec string) (*Recurrence, error) {
+	r := &Recurrence{
+		Spec:     trim(spec),
+		Start:    d,
+		Interval: 1,
+		Weekday:  d.ToTime().Weekday(),
+		MonthDay: d.Day,
+	}
+
+	rule := strings.ToLower(r.Spec)
+	if matches := untilFinder.FindStringSubmatch(rule); matches != nil {
+		until, err := YmdToDate(matches[2])
+		if err != nil {
+			return nil, fmt.Errorf("invalid end date in recurrence %q: %v", r.Spec, err)
+		}
+		rule = matches[1]
+		r.Until = until
+	}
+
+	for re, f := range recurrenceMatchers {
+		if matches := re.FindStringSubmatch(rule); matches != nil {
+			if err := f(r, matches); err != nil {
+				return nil, err
+			}
+			return r, nil
+		}
+	}
+
+	return nil, fmt.Errorf("no valid recurrence parser found for spec %q", r.Spec)
+}
@@ -0,0 +1,142 @@
+package parser
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestRecurrence(t *testing.T) {
+	// 2001-02-05 is a Monday.
+	tests := map[string]struct {
+		start       Date
+		spec        string
+		occurs      []string
+		doesntOccur []string
+	}{
+		"Every day": {
+			mustYmdToDate("2001-02-05"), "every day",
+			[]string{"2001-02-06", "2001-02-07", "2002-01-01"},
+			[]string{"2001-02-04", "2001-02-05"},
+		},
+		"Daily": {
+			mustYmdToDate("2001-02-05"), "Daily",
+			[]string{"2001-02-06"},
+			[]string{"2001-02-05"},
+		},
+		"Every weekday name": {
+			mustYmdToDate("2001-02-05"), "every friday",
+			[]string{"2001-02-09", "2001-02-16", "2001-03-02"},
+			[]string{"2001-02-05", "2001-02-08", "2001-02-10", "2001-02-02"},
+		},
+		"Every abbreviated weekday": {
+			mustYmdToDate("2001-02-05"), "every mon",
+			[]string{"2001-02-12", "2001-02-19"},
+			[]string{"2001-02-05", "2001-02-13"},
+		},
+		"Weekly is the same weekday": {
+			mustYmdToDate("2001-02-05"), "weekly",
+			[]string{"2001-02-12", "2001-02-19"},
+			[]string{"2001-02-05", "2001-02-13"},
+		},
+		"Every 2 weeks": {
+			mustYmdToDate("2001-02-05"), "every 2 weeks",
+			[]string{"2001-02-19", "2001-03-05"},
+			[]string{"2001-02-12", "2001-02-26", "2001-02-20"},
+		},
+		"Every 3 days": {
+			mustYmdToDate("2001-02-05"), "every 3 days",
+			[]string{"2001-02-08", "2001-02-11"},
+			[]string{"2001-02-06", "2001-02-07", "2001-02-09"},
+		},
+		"Monthly on the 1st": {
+			mustYmdToDate("2001-02-05"), "monthly on the 1st",
+			[]string{"2001-03-01", "2001-04-01", "2002-01-01"},
+			[]string{"2001-02-01", "2001-03-02"},
+		},
+		"Monthly on the 31st in short months": {
+			mustYmdToDate("2001-01-05"), "every month on the 31st",
+			[]string{"2001-01-31", "2001-02-28", "2001-04-30"},
+			[]string{"2001-03-30", "2001-03-01"},
+		},
+		"Monthly": {
+			mustYmdToDate("2001-02-05"), "monthly",
+			[]string{"2001-03-05", "2001-04-05"},
+			[]string{"2001-02-05", "2001-03-06"},
+		},
+		"Every 3 months": {
+			mustYmdToDate("2001-02-05"), "every 3 months",
+			[]string{"2001-05-05", "2001-08-05"},
+			[]string{"2001-03-05", "2001-04-05"},
+		},
+		"Yearly on a leap day": {
+			mustYmdToDate("2000-02-29"), "yearly",
+			[]string{"2001-02-28", "2004-02-29"},
+			[]string{"2000-02-29", "2001-03-01", "2004-02-28"},
+		},
+		"Until": {
+			mustYmdToDate("2001-02-05"), "every friday until 2001-02-16",
+			[]string{"2001-02-09", "2001-02-16"},
+			[]string{"2001-02-23"},
+		},
+	}
+	for n, test := range tests {
+		t.Run(n, func(t *testing.T) {
+			r, err := ParseRecurrence(test.start, test.spec)
+			if err != nil {
+				t.Fatalf("Expected no error but got [%v]", err)
+			}
+			for _, d := range test.occurs {
+				if !r.Occurs(mustYmdToDate(d)) {
+					t.Errorf("Expected %q to occur on %s", test.spec, d)
+				}
+			}
+			for _, d := range test.doesntOccur {
+				if r.Occurs(mustYmdToDate(d)) {
+					t.Errorf("Expected %q not to occur on %s", test.spec, d)
+				}
+			}
+		})
+	}
+}
+
+func TestRecurrenceSkipNonWorkingDays(t *testing.T) {
+	// 2001-02-03 is a Saturday.
+	r, err := ParseRecurrence(mustYmdToDate("2001-02-01"), "every saturday")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.skipNonWorkingDays = true
+	r.workWeek = DefaultWorkWeek
+
+	for d, want := range map[string]bool{
+		"2001-02-02": false,
+		"2001-02-03": false,
+		"2001-02-04": false,
+		"2001-02-05": true,
+		"2001-02-06": false,
+	} {
+		if got := r.Occurs(mustYmdToDate(d)); got != want {
+			t.Errorf("Expected occurrence on %s to be %t, was %t", d, want, got)
+		}
+	}
+}
+
+func TestInvalidRecurrence(t *testing.T) {
+	tests := map[string]error{
+		"every fryday":               fmt.Errorf("no valid recurrence parser found for spec \"every fryday\""),
+		"every 0 days":               fmt.Errorf("invalid interval \"0\" in recurrence \"every 0 days\""),
+		"monthly on the 32nd":        fmt.Errorf("invalid day of the month \"32\" in recurrence \"monthly on the 32nd\""),
+		"every day until 2001-02-30": fmt.Errorf("invalid end date in recurrence \"every day until 2001-02-30\": parsing time \"2001-02-30\": day out of range"),
+	}
+	for spec, want := range tests {
+		t.Run(spec, func(t *testing.T) {
+			_, err := ParseRecurrence(mustYmdToDate("2001-02-05"), spec)
+			if err == nil {
+				t.Fatalf("Expected an error but didn't get one")
+			}
+			if err.Error() != want.Error() {
+				t.Fatalf("Expected error to be [%v], was [%v]", want, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,307 @@
+/**
+ * Copyright 2018 Google LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the license.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specified language governing permissions and
+ * limitations under the License.
+ */
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/achew22/logbook/parser"
+	"github.com/achew22/logbook/templater"
+)
+
+// APIPrefix is where the JSON API is served.
+const APIPrefix = "/api/v1/"
+
+// maxReminderDays limits how many days of reminders can be requested at once.
+const maxReminderDays = 366
+
+// maxNoteSize limits how big a note can be.
+const maxNoteSize = 64 << 10
+
+type apiReminder struct {
+	Date   string `json:"date"`
+	Origin string `json:"origin"`
+	Text   string `json:"text"`
+	parser.Position
+}
+
+type apiError struct {
+	Date    string `json:"date"`
+	Message string `json:"message"`
+	parser.Position
+}
+
+type noteRequest struct {
+	Text string `json:"text"`
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		log.Printf("Unable to write the response: %v", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, code int, format string, a ...interface{}) {
+	writeJSON(w, code, map[string]string{
+		"error": fmt.Sprintf(format, a...),
+	})
+}
+
+// allowMethods responds with an error and returns false if the request isn't
+// one of methods.
+func allowMethods(w http.ResponseWriter, r *http.Request, methods ...string) bool {
+	for _, m := range methods {
+		if r.Method == m {
+			return true
+		}
+	}
+	w.Header().Set("Allow", strings.Join(methods, ", "))
+	writeError(w, http.StatusMethodNotAllowed, "%s is not allowed", r.Method)
+	return false
+}
+
+// handleAPI routes:
+//
+//	GET  /api/v1/entries
+//	GET  /api/v1/entries/{date}
+//	POST /api/v1/entries/{date}/notes
+//	GET  /api/v1/reminders?from={date}&to={date}
+//	GET  /api/v1/errors
+func (s *Server) handleAPI(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, APIPrefix), "/"), "/")
+
+	switch {
+	case len(parts) == 1 && parts[0] == "entries":
+		if allowMethods(w, r, "GET") {
+			s.apiEntries(w, r)
+		}
+	case len(parts) == 2 && parts[0] == "entries":
+		if allowMethods(w, r, "GET") {
+			s.apiEntry(w, r, parts[1])
+		}
+	case len(parts) == 3 && parts[0] == "entries" && parts[2] == "notes":
+		if allowMethods(w, r, "POST") {
+			s.apiAddNote(w, r, parts[1])
+		}
+	case len(parts) == 1 && parts[0] == "reminders":
+		if allowMethods(w, r, "GET") {
+			s.apiReminders(w, r)
+		}
+	case len(parts) == 1 && parts[0] == "errors":
+		if allowMethods(w, r, "GET") {
+			s.apiErrors(w, r)
+		}
+	default:
+		writeError(w, http.StatusNotFound, "%s was not found", r.URL.Path)
+	}
+}
+
+func sortedEntries(entries map[parser.Date]*parser.LogEntry) []*parser.LogEntry {
+	var out []*parser.LogEntry
+	for _, entry := range entries {
+		out = append(out, entry)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Date.Before(out[j].Date)
+	})
+	return out
+}
+
+func (s *Server) apiEntries(w http.ResponseWriter, r *http.Request) {
+	entries := sortedEntries(s.snapshot())
+	if entries == nil {
+		entries = []*parser.LogEntry{}
+	}
+	writeJSON(w, http.StatusOK, entries)
+}
+
+func (s *Server) apiEntry(w http.ResponseWriter, r *http.Request, date string) {
+	d, err := parser.YmdToDate(date)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid date %q", date)
+		return
+	}
+
+	entry, ok := s.snapshot()[d]
+	if !ok {
+		writeError(w, http.StatusNotFound, "There is no entry for %s", d.ToYmd())
+		return
+	}
+	writeJSON(w, http.StatusOK, entry)
+}
+
+func (s *Server) apiReminders(w http.ResponseWriter, r *http.Request) {
+	from := s.today()
+	if v := r.URL.Query().Get("from"); v != "" {
+		var err error
+		if from, err = parser.YmdToDate(v); err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid from date %q", v)
+			return
+		}
+	}
+	to := parser.TimeToDate(from.ToTime().AddDate(0, 0, UpcomingDays-1))
+	if v := r.URL.Query().Get("to"); v != "" {
+		var err error
+		if to, err = parser.YmdToDate(v); err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid to date %q", v)
+			return
+		}
+	}
+	if to.Before(from) {
+		writeError(w, http.StatusBadRequest, "to is before from")
+		return
+	}
+	if from.DaysUntil(to) >= maxReminderDays {
+		writeError(w, http.StatusBadRequest, "Reminders can only be requested for up to %d days at a time", maxReminderDays)
+		return
+	}
+
+	entries := s.snapshot()
+	reminders := []*apiReminder{}
+	for d := from; !d.After(to); d = parser.TimeToDate(d.ToTime().AddDate(0, 0, 1)) {
+		byOrigin := parser.RemindersOn(entries, d)
+		var origins []parser.Date
+		for origin := range byOrigin {
+			origins = append(origins, origin)
+		}
+		sort.Slice(origins, func(i, j int) bool {
+			return origins[i].Before(origins[j])
+		})
+
+		for _, origin := range origins {
+			for _, reminder := range byOrigin[origin] {
+				reminders = append(reminders, &apiReminder{
+					Date:     d.ToYmd(),
+					Origin:   origin.ToYmd(),
+					Text:     reminder.Text,
+					Position: reminder.Position,
+				})
+			}
+		}
+	}
+	writeJSON(w, http.StatusOK, reminders)
+}
+
+func (s *Server) apiErrors(w http.ResponseWriter, r *http.Request) {
+	errors := []*apiError{}
+	for _, entry := range sortedEntries(s.snapshot()) {
+		for _, err := range entry.Errors {
+			errors = append(errors, &apiError{
+				Date:     entry.Date.ToYmd(),
+				Message:  err.Message,
+				Position: err.Position,
+			})
+		}
+	}
+	writeJSON(w, http.StatusOK, errors)
+}
+
+// apiAddNote appends a note to the entry for date, generating the entry
+// first if it doesn't exist.
+func (s *Server) apiAddNote(w http.ResponseWriter, r *http.Request, date string) {
+	if !s.AllowWrites {
+		writeError(w, http.StatusForbidden, "The logbook is read-only")
+		return
+	}
+
+	d, err := parser.YmdToDate(date)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid date %q", date)
+		return
+	}
+
+	// Browsers only send JSON to other sites after asking them first, and say
+	// which page sent it, so neither check lets other pages write to the
+	// logbook.
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType != "application/json" {
+		writeError(w, http.StatusUnsupportedMediaType, "Notes must be sent as application/json")
+		return
+	}
+	if !sameOrigin(r) {
+		writeError(w, http.StatusForbidden, "Notes can't be added from other sites")
+		return
+	}
+
+	var note noteRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxNoteSize)).Decode(&note); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid note: %v", err)
+		return
+	}
+	if strings.TrimSpace(note.Text) == "" {
+		writeError(w, http.StatusBadRequest, "The note is empty")
+		return
+	}
+
+	if err := s.appendNote(d, note.Text); err != nil {
+		log.Printf("Unable to add a note to %s: %v", d.ToYmd(), err)
+		writeError(w, http.StatusInternalServerError, "Unable to add the note")
+		return
+	}
+	if _, err := s.Reload(); err != nil {
+		log.Printf("Unable to reload the logbook: %v", err)
+	}
+
+	writeJSON(w, http.StatusCreated, s.snapshot()[d])
+}
+
+// sameOrigin reports whether r came from a page served by this server, or
+// from something other than a browser, which doesn't send an Origin.
+func sameOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	return err == nil && u.Host == r.Host
+}
+
+func (s *Server) appendNote(d parser.Date, text string) error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	path := s.parser.PathFor(d)
+	existing, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		// Generate the entry like "logbook new" would.
+		generated, err := templater.Print(s.config, s.snapshot(), d)
+		if err != nil {
+			return err
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0777); err != nil {
+			return err
+		}
+		existing = []byte(generated)
+	} else if err != nil {
+		return err
+	}
+
+	contents := strings.TrimRight(string(existing), "\n") + "\n\n" + strings.TrimSpace(text) + "\n"
+	return ioutil.WriteFile(path, []byte(contents), 0666)
+}
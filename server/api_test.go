@@ -0,0 +1,331 @@
+package server
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/achew22/logbook/parser"
+)
+
+func getJSON(t *testing.T, s *Server, path string, v interface{}) int {
+	code, body := get(t, s, path)
+	if err := json.Unmarshal([]byte(body), v); err != nil {
+		t.Fatalf("GET %s returned invalid JSON: %v\n%s", path, err, body)
+	}
+	return code
+}
+
+func postNote(t *testing.T, s *Server, path, body string) (int, string) {
+	return postNoteWithHeaders(t, s, path, body, map[string]string{"Content-Type": "application/json"})
+}
+
+func postNoteWithHeaders(t *testing.T, s *Server, path, body string, headers map[string]string) (int, string) {
+	r := httptest.NewRequest("POST", path, strings.NewReader(body))
+	for k, v := range headers {
+		r.Header.Set(k, v)
+	}
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, r)
+	return w.Code, w.Body.String()
+}
+
+func TestAPIEntries(t *testing.T) {
+	s, _, cleanup := newTestServer(t)
+	defer cleanup()
+
+	var entries []struct {
+		Date string `json:"date"`
+	}
+	if code := getJSON(t, s, "/api/v1/entries", &entries); code != http.StatusOK {
+		t.Fatalf("Got status %d", code)
+	}
+
+	var got []string
+	for _, entry := range entries {
+		got = append(got, entry.Date)
+	}
+	want := []string{"2000-01-03", "2000-01-04", "2000-01-06"}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("Differences:\n%s", diff)
+	}
+}
+
+func TestAPIEntry(t *testing.T) {
+	s, _, cleanup := newTestServer(t)
+	defer cleanup()
+
+	var entry struct {
+		Date           string `json:"date"`
+		PastReferences map[string][]struct {
+			Text string `json:"text"`
+			Line int    `json:"line"`
+		} `json:"pastReferences"`
+	}
+	if code := getJSON(t, s, "/api/v1/entries/2000-01-04", &entry); code != http.StatusOK {
+		t.Fatalf("Got status %d", code)
+	}
+	refs := entry.PastReferences["2000-01-03"]
+	if len(entry.PastReferences) != 1 || len(refs) != 1 || refs[0].Text != "Send the launch notes & slides" || refs[0].Line != 7 {
+		t.Errorf("Got %+v", entry.PastReferences)
+	}
+
+	for path, want := range map[string]int{
+		"/api/v1/entries/2000-02-01":   http.StatusNotFound,
+		"/api/v1/entries/yesterday":    http.StatusBadRequest,
+		"/api/v1/entries/2000-01-04/x": http.StatusNotFound,
+		"/api/v1/nothing":              http.StatusNotFound,
+	} {
+		var e map[string]string
+		if code := getJSON(t, s, path, &e); code != want {
+			t.Errorf("GET %s: got status %d, want %d", path, code, want)
+		}
+		if e["error"] == "" {
+			t.Errorf("GET %s: expected an error message, got %v", path, e)
+		}
+	}
+}
+
+// at returns where a line of the entry for ymd in dir starts.
+func at(dir, ymd string, line int) parser.Position {
+	return parser.Position{Path: filepath.Join(dir, ymd+".md"), Line: line, Column: 1}
+}
+
+func TestAPIReminders(t *testing.T) {
+	s, dir, cleanup := newTestServer(t)
+	defer cleanup()
+
+	var got []*apiReminder
+	if code := getJSON(t, s, "/api/v1/reminders", &got); code != http.StatusOK {
+		t.Fatalf("Got status %d", code)
+	}
+	want := []*apiReminder{
+		{Date: "2000-01-04", Origin: "2000-01-03", Text: "Send the launch notes & slides", Position: at(dir, "2000-01-03", 7)},
+		{Date: "2000-01-06", Origin: "2000-01-04", Text: "Write the retrospective", Position: at(dir, "2000-01-04", 3)},
+	}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("Differences:\n%s", diff)
+	}
+
+	got = nil
+	if code := getJSON(t, s, "/api/v1/reminders?from=2000-01-04&to=2000-01-05", &got); code != http.StatusOK {
+		t.Fatalf("Got status %d", code)
+	}
+	want = []*apiReminder{
+		{Date: "2000-01-04", Origin: "2000-01-03", Text: "Send the launch notes & slides", Position: at(dir, "2000-01-03", 7)},
+	}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("Differences:\n%s", diff)
+	}
+
+	for _, query := range []string{
+		"from=tomorrow",
+		"to=2000-13-01",
+		"from=2000-01-04&to=2000-01-03",
+		"from=2000-01-01&to=2010-01-01",
+	} {
+		if code, _ := get(t, s, "/api/v1/reminders?"+query); code != http.StatusBadRequest {
+			t.Errorf("%s: got status %d, want %d", query, code, http.StatusBadRequest)
+		}
+	}
+}
+
+func TestAPIRemindersSkipUnknownMarkdown(t *testing.T) {
+	s, dir, cleanup := newTestServer(t)
+	defer cleanup()
+
+	writeEntry(t, dir, "2000-01-05.md", "# Andrew Allen - 2000-01-05\n\nRead [the doc](http://example.com/doc), which is **mostly** done.\n")
+	if _, err := s.Reload(); err != nil {
+		t.Fatal(err)
+	}
+
+	var got []*apiReminder
+	if code := getJSON(t, s, "/api/v1/reminders?from=2000-01-05&to=2000-01-05", &got); code != http.StatusOK {
+		t.Fatalf("Got status %d", code)
+	}
+	if len(got) != 0 {
+		t.Errorf("Expected no reminders, got %+v", got[0])
+	}
+}
+
+func TestAPIErrors(t *testing.T) {
+	s, _, cleanup := newTestServer(t)
+	defer cleanup()
+
+	var got []*apiError
+	if code := getJSON(t, s, "/api/v1/errors", &got); code != http.StatusOK {
+		t.Fatalf("Got status %d", code)
+	}
+	if len(got) == 0 {
+		t.Fatalf("Expected parse errors")
+	}
+	for _, e := range got {
+		if e.Date != "2000-01-03" || e.Message == "" || !strings.HasSuffix(e.Path, "2000-01-03.md") {
+			t.Errorf("Unexpected error %+v", e)
+		}
+	}
+}
+
+func TestAPIMethods(t *testing.T) {
+	s, _, cleanup := newTestServer(t)
+	defer cleanup()
+
+	code, _ := postNote(t, s, "/api/v1/entries", "{}")
+	if code != http.StatusMethodNotAllowed {
+		t.Errorf("Got status %d, want %d", code, http.StatusMethodNotAllowed)
+	}
+	if code, _ := get(t, s, "/api/v1/entries/2000-01-04/notes"); code != http.StatusMethodNotAllowed {
+		t.Errorf("Got status %d, want %d", code, http.StatusMethodNotAllowed)
+	}
+}
+
+func TestAPIAddNoteReadOnly(t *testing.T) {
+	s, dir, cleanup := newTestServer(t)
+	defer cleanup()
+
+	before, err := ioutil.ReadFile(filepath.Join(dir, "2000-01-04.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if code, _ := postNote(t, s, "/api/v1/entries/2000-01-04/notes", `{"text": "tomorrow: Hi"}`); code != http.StatusForbidden {
+		t.Errorf("Got status %d, want %d", code, http.StatusForbidden)
+	}
+	after, err := ioutil.ReadFile(filepath.Join(dir, "2000-01-04.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(before) != string(after) {
+		t.Errorf("The entry changed:\n%s", after)
+	}
+}
+
+func TestAPIAddNote(t *testing.T) {
+	s, dir, cleanup := newTestServer(t)
+	defer cleanup()
+	s.AllowWrites = true
+
+	code, body := postNote(t, s, "/api/v1/entries/2000-01-04/notes", `{"text": "tomorrow: Book the room\n"}`)
+	if code != http.StatusCreated {
+		t.Fatalf("Got status %d: %s", code, body)
+	}
+
+	got, err := ioutil.ReadFile(filepath.Join(dir, "2000-01-04.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `# Andrew Allen - 2000-01-04
+
+in 2 days: Write the retrospective
+
+tomorrow: Book the room
+`
+	if string(got) != want {
+		t.Errorf("Got %q, want %q", got, want)
+	}
+
+	// The new reminder shows up right away.
+	var reminders []*apiReminder
+	getJSON(t, s, "/api/v1/reminders?from=2000-01-05&to=2000-01-05", &reminders)
+	wantReminders := []*apiReminder{
+		{Date: "2000-01-05", Origin: "2000-01-04", Text: "Book the room", Position: at(dir, "2000-01-04", 5)},
+	}
+	if diff := cmp.Diff(reminders, wantReminders); diff != "" {
+		t.Errorf("Differences:\n%s", diff)
+	}
+}
+
+func TestAPIAddNoteToNewEntry(t *testing.T) {
+	s, dir, cleanup := newTestServer(t)
+	defer cleanup()
+	s.AllowWrites = true
+
+	if code, body := postNote(t, s, "/api/v1/entries/2000-01-05/notes", `{"text": "Met with Sam"}`); code != http.StatusCreated {
+		t.Fatalf("Got status %d: %s", code, body)
+	}
+
+	got, err := ioutil.ReadFile(filepath.Join(dir, "2000-01-05.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"# Andrew Allen - 2000-01-05", "## Parse errors", "\n\nMet with Sam\n"} {
+		if !strings.Contains(string(got), want) {
+			t.Errorf("Expected the entry to contain %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestAPIAddInvalidNote(t *testing.T) {
+	s, _, cleanup := newTestServer(t)
+	defer cleanup()
+	s.AllowWrites = true
+
+	for _, body := range []string{"", "text", `{"text": "  "}`} {
+		if code, _ := postNote(t, s, "/api/v1/entries/2000-01-04/notes", body); code != http.StatusBadRequest {
+			t.Errorf("%q: got status %d, want %d", body, code, http.StatusBadRequest)
+		}
+	}
+	if code, _ := postNote(t, s, "/api/v1/entries/someday/notes", `{"text": "Hi"}`); code != http.StatusBadRequest {
+		t.Errorf("Got status %d, want %d", code, http.StatusBadRequest)
+	}
+}
+
+func TestAPIAddNoteFromOtherSites(t *testing.T) {
+	s, dir, cleanup := newTestServer(t)
+	defer cleanup()
+	s.AllowWrites = true
+
+	before, err := ioutil.ReadFile(filepath.Join(dir, "2000-01-04.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, test := range map[string]struct {
+		headers map[string]string
+		want    int
+	}{
+		"form content type": {
+			headers: map[string]string{"Content-Type": "text/plain"},
+			want:    http.StatusUnsupportedMediaType,
+		},
+		"no content type": {
+			headers: map[string]string{},
+			want:    http.StatusUnsupportedMediaType,
+		},
+		"other origin": {
+			headers: map[string]string{"Content-Type": "application/json", "Origin": "http://evil.example"},
+			want:    http.StatusForbidden,
+		},
+		"null origin": {
+			headers: map[string]string{"Content-Type": "application/json", "Origin": "null"},
+			want:    http.StatusForbidden,
+		},
+	} {
+		code, body := postNoteWithHeaders(t, s, "/api/v1/entries/2000-01-04/notes", `{"text": "tomorrow: injected"}`, test.headers)
+		if code != test.want {
+			t.Errorf("%s: got status %d, want %d: %s", name, code, test.want, body)
+		}
+	}
+
+	after, err := ioutil.ReadFile(filepath.Join(dir, "2000-01-04.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(after) != string(before) {
+		t.Errorf("Expected the entry to be unchanged, got %q", after)
+	}
+
+	// Pages served from the logbook itself can add notes.
+	code, body := postNoteWithHeaders(t, s, "/api/v1/entries/2000-01-04/notes", `{"text": "tomorrow: Book the room"}`, map[string]string{
+		"Content-Type": "application/json; charset=utf-8",
+		"Origin":       "http://example.com",
+	})
+	if code != http.StatusCreated {
+		t.Errorf("Got status %d: %s", code, body)
+	}
+}
@@ -0,0 +1,297 @@
+/**
+ * Copyright 2018 Google LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the license.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specified language governing permissions and
+ * limitations under the License.
+ */
+// Package server serves a read-only view of the logbook over HTTP.
+package server
+
+import (
+	"crypto/sha1"
+	"fmt"
+	"html/template"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	blackfriday "gopkg.in/russross/blackfriday.v2"
+
+	"github.com/achew22/logbook/config"
+	"github.com/achew22/logbook/parser"
+)
+
+// UpcomingDays is how many days, starting with today, the reminders page
+// shows.
+const UpcomingDays = 14
+
+type Server struct {
+	// AllowWrites lets notes be added to entries through the API.
+	AllowWrites bool
+
+	config *config.Config
+	parser *parser.Parser
+
+	// today is replaced in tests.
+	today func() parser.Date
+
+	// reloadMu makes sure only one reload runs at a time, since the parser
+	// can only parse one logbook at a time.
+	reloadMu    sync.Mutex
+	fingerprint string
+
+	mu      sync.RWMutex
+	entries map[parser.Date]*parser.LogEntry
+
+	// writeMu makes sure only one note is added at a time.
+	writeMu sync.Mutex
+
+	mux *http.ServeMux
+}
+
+// New creates a Server for the logbook described by c. Call Reload before
+// serving anything.
+func New(c *config.Config) *Server {
+	s := &Server{
+		config: c,
+		parser: parser.New(c),
+		today: func() parser.Date {
+			return parser.TimeToDate(time.Now())
+		},
+		entries: map[parser.Date]*parser.LogEntry{},
+		mux:     http.NewServeMux(),
+	}
+
+	s.mux.HandleFunc("/", s.handleIndex)
+	s.mux.HandleFunc("/entries/", s.handleEntry)
+	s.mux.HandleFunc("/reminders", s.handleReminders)
+	s.mux.HandleFunc("/errors", s.handleErrors)
+	s.mux.HandleFunc(APIPrefix, s.handleAPI)
+	return s
+}
+
+// UseCache caches parsed entries at path, like parser.Parser.UseCache.
+func (s *Server) UseCache(path string) {
+	s.reloadMu.Lock()
+	defer s.reloadMu.Unlock()
+	s.parser.UseCache(path)
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// fingerprint summarizes the names, sizes and modification times of every
+// file in dir, so that it changes whenever one of them does. A logbook that
+// doesn't exist yet has an empty fingerprint, like it has no entries.
+func fingerprint(dir string) (string, error) {
+	h := sha1.New()
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(h, "%s %d %d\n", path, info.Size(), info.ModTime().UnixNano())
+		return nil
+	})
+	if path, ok := err.(*os.PathError); ok && os.IsNotExist(err) && path.Path == dir {
+		return "", nil
+	}
+	return fmt.Sprintf("%x", h.Sum(nil)), err
+}
+
+// Reload parses the logbook again if anything in it has changed since it was
+// last parsed. It returns whether it did.
+func (s *Server) Reload() (bool, error) {
+	s.reloadMu.Lock()
+	defer s.reloadMu.Unlock()
+
+	f, err := fingerprint(s.config.LogPath)
+	if err != nil {
+		return false, err
+	}
+	if f == s.fingerprint {
+		return false, nil
+	}
+
+	entries, err := s.parser.Parse()
+	if warnings, ok := err.(parser.Warnings); ok {
+		for _, w := range warnings {
+			log.Printf("Warning: %v", w)
+		}
+	} else if err != nil {
+		return false, err
+	}
+
+	s.mu.Lock()
+	s.entries = entries
+	s.mu.Unlock()
+	s.fingerprint = f
+	return true, nil
+}
+
+// Watch reloads the logbook every interval, until stop is closed, so that
+// edits show up without restarting the server.
+func (s *Server) Watch(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			if reloaded, err := s.Reload(); err != nil {
+				log.Printf("Unable to reload the logbook: %v", err)
+			} else if reloaded {
+				log.Printf("Reloaded the logbook")
+			}
+		}
+	}
+}
+
+// snapshot returns the entries as of the last reload. They must not be
+// modified.
+func (s *Server) snapshot() map[parser.Date]*parser.LogEntry {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.entries
+}
+
+func (s *Server) render(w http.ResponseWriter, name string, data interface{}) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := pages.ExecuteTemplate(w, name, data); err != nil {
+		log.Printf("Unable to render %s: %v", name, err)
+	}
+}
+
+// entriesOnDisk returns the entries that were read from a file, newest first.
+func entriesOnDisk(entries map[parser.Date]*parser.LogEntry) []*parser.LogEntry {
+	var out []*parser.LogEntry
+	for _, entry := range entries {
+		if entry.OnDisk {
+			out = append(out, entry)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Date.After(out[j].Date)
+	})
+	return out
+}
+
+func (s *Server) handleIndex(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+
+	s.render(w, "index", &indexPage{
+		Title:   s.config.Name + "'s logbook",
+		Entries: entriesOnDisk(s.snapshot()),
+	})
+}
+
+func (s *Server) handleEntry(w http.ResponseWriter, r *http.Request) {
+	d, err := parser.YmdToDate(strings.TrimPrefix(r.URL.Path, "/entries/"))
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	entries := s.snapshot()
+	entry, ok := entries[d]
+	if !ok || !entry.OnDisk {
+		http.NotFound(w, r)
+		return
+	}
+
+	b, err := ioutil.ReadFile(entry.Path)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Unable to read the entry for %s", d.ToYmd()), http.StatusInternalServerError)
+		return
+	}
+
+	page := &entryPage{
+		Title: d.ToYmd(),
+		Entry: entry,
+		// HTML and links to anything but safe schemes, like javascript:,
+		// are skipped since the entries might be shared with people who
+		// didn't write them.
+		HTML: template.HTML(blackfriday.Run(b, blackfriday.WithRenderer(blackfriday.NewHTMLRenderer(blackfriday.HTMLRendererParameters{
+			Flags: blackfriday.CommonHTMLFlags | blackfriday.SkipHTML | blackfriday.Safelink,
+		})))),
+	}
+	// Entries are newest first.
+	onDisk := entriesOnDisk(entries)
+	for i, e := range onDisk {
+		if e != entry {
+			continue
+		}
+		if i+1 < len(onDisk) {
+			page.Previous = onDisk[i+1]
+		}
+		if i > 0 {
+			page.Next = onDisk[i-1]
+		}
+	}
+
+	s.render(w, "entry", page)
+}
+
+func (s *Server) handleReminders(w http.ResponseWriter, r *http.Request) {
+	entries := s.snapshot()
+
+	page := &remindersPage{
+		Title: "Upcoming reminders",
+	}
+	d := s.today()
+	for i := 0; i < UpcomingDays; i++ {
+		reminders := parser.RemindersOn(entries, d)
+		if len(reminders) > 0 {
+			day := &reminderDay{Date: d}
+			for origin, messages := range reminders {
+				day.Groups = append(day.Groups, &reminderGroup{
+					Origin:    origin,
+					Reminders: messages,
+				})
+			}
+			sort.Slice(day.Groups, func(i, j int) bool {
+				return day.Groups[i].Origin.Before(day.Groups[j].Origin)
+			})
+			page.Days = append(page.Days, day)
+		}
+		d = parser.TimeToDate(d.ToTime().AddDate(0, 0, 1))
+	}
+
+	s.render(w, "reminders", page)
+}
+
+func (s *Server) handleErrors(w http.ResponseWriter, r *http.Request) {
+	page := &errorsPage{
+		Title: "Parse errors",
+	}
+	for _, entry := range s.snapshot() {
+		if len(entry.Errors) > 0 {
+			page.Entries = append(page.Entries, entry)
+		}
+	}
+	sort.Slice(page.Entries, func(i, j int) bool {
+		return page.Entries[i].Date.Before(page.Entries[j].Date)
+	})
+
+	s.render(w, "errors", page)
+}
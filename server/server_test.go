@@ -0,0 +1,275 @@
+package server
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/achew22/logbook/config"
+	"github.com/achew22/logbook/parser"
+)
+
+func ymd(s string) parser.Date {
+	d, err := parser.YmdToDate(s)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+func writeEntry(t *testing.T, dir, name, contents string) {
+	if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(contents), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// newTestServer creates a Server for a logbook with a couple of entries in
+// it, where today is 2000-01-04.
+func newTestServer(t *testing.T) (*Server, string, func()) {
+	dir, err := ioutil.TempDir("", "logbook")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	writeEntry(t, dir, "2000-01-03.md", `# Andrew Allen - 2000-01-03
+
+Shipped the *launch*.
+
+<script>alert("hi")</script>
+
+tomorrow: Send the launch notes & slides
+
+someday: Clean up
+`)
+	writeEntry(t, dir, "2000-01-04.md", `# Andrew Allen - 2000-01-04
+
+in 2 days: Write the retrospective
+`)
+
+	s := New(&config.Config{
+		Name:    "Andrew Allen",
+		LogPath: dir,
+	})
+	s.today = func() parser.Date {
+		return ymd("2000-01-04")
+	}
+	if _, err := s.Reload(); err != nil {
+		t.Fatal(err)
+	}
+
+	return s, dir, func() {
+		os.RemoveAll(dir)
+	}
+}
+
+func get(t *testing.T, s *Server, path string) (int, string) {
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+	return w.Code, w.Body.String()
+}
+
+func assertContains(t *testing.T, body string, want ...string) {
+	for _, w := range want {
+		if !strings.Contains(body, w) {
+			t.Errorf("Expected the page to contain %q:\n%s", w, body)
+		}
+	}
+}
+
+func TestIndex(t *testing.T) {
+	s, _, cleanup := newTestServer(t)
+	defer cleanup()
+
+	code, body := get(t, s, "/")
+	if code != http.StatusOK {
+		t.Fatalf("Got status %d", code)
+	}
+	assertContains(t, body,
+		"<h1>Andrew Allen&#39;s logbook</h1>",
+		`<li><a href="/entries/2000-01-04">2000-01-04</a> Tuesday</li>
+<li><a href="/entries/2000-01-03">2000-01-03</a> Monday</li>`,
+	)
+}
+
+func TestEntry(t *testing.T) {
+	s, _, cleanup := newTestServer(t)
+	defer cleanup()
+
+	code, body := get(t, s, "/entries/2000-01-03")
+	if code != http.StatusOK {
+		t.Fatalf("Got status %d", code)
+	}
+	assertContains(t, body,
+		"<h1>Andrew Allen - 2000-01-03</h1>",
+		"<p>Shipped the <em>launch</em>.</p>",
+		`<a href="/entries/2000-01-04">2000-01-04 &rarr;</a>`,
+	)
+	if strings.Contains(body, "<script>") {
+		t.Errorf("Expected HTML in the entry to be skipped:\n%s", body)
+	}
+
+	for _, path := range []string{"/entries/2000-01-05", "/entries/yesterday", "/nothing"} {
+		if code, _ := get(t, s, path); code != http.StatusNotFound {
+			t.Errorf("Got status %d for %s, want %d", code, path, http.StatusNotFound)
+		}
+	}
+}
+
+func TestEntryNamedByHand(t *testing.T) {
+	s, dir, cleanup := newTestServer(t)
+	defer cleanup()
+
+	writeEntry(t, dir, "2000-1-5.md", "# Andrew Allen - 2000-01-05\n\nWritten by hand.\n")
+	if _, err := s.Reload(); err != nil {
+		t.Fatal(err)
+	}
+
+	code, body := get(t, s, "/entries/2000-01-05")
+	if code != http.StatusOK {
+		t.Fatalf("Got status %d", code)
+	}
+	assertContains(t, body, "<p>Written by hand.</p>")
+}
+
+func TestEntryLinks(t *testing.T) {
+	s, dir, cleanup := newTestServer(t)
+	defer cleanup()
+
+	writeEntry(t, dir, "2000-01-05.md", `# Andrew Allen - 2000-01-05
+
+Read [the notes](https://example.com/notes) and [this](javascript:alert(1)).
+`)
+	if _, err := s.Reload(); err != nil {
+		t.Fatal(err)
+	}
+
+	_, body := get(t, s, "/entries/2000-01-05")
+	assertContains(t, body, `<a href="https://example.com/notes">the notes</a>`)
+	if strings.Contains(body, "javascript:") {
+		t.Errorf("Expected the javascript: link to be skipped:\n%s", body)
+	}
+}
+
+func TestReminders(t *testing.T) {
+	s, _, cleanup := newTestServer(t)
+	defer cleanup()
+
+	code, body := get(t, s, "/reminders")
+	if code != http.StatusOK {
+		t.Fatalf("Got status %d", code)
+	}
+	assertContains(t, body,
+		`<h2>2000-01-04 Tuesday</h2>
+<p>From 2000-01-03:</p>
+<ul>
+<li>Send the launch notes &amp; slides</li>
+</ul>
+<h2>2000-01-06 Thursday</h2>
+<p>From 2000-01-04:</p>
+<ul>
+<li>Write the retrospective</li>
+</ul>`,
+	)
+	if strings.Contains(body, "Clean up") {
+		t.Errorf("Expected reminders from before today to be left out:\n%s", body)
+	}
+}
+
+func TestRemindersSkipUnknownMarkdown(t *testing.T) {
+	s, dir, cleanup := newTestServer(t)
+	defer cleanup()
+
+	writeEntry(t, dir, "2000-01-05.md", "# Andrew Allen - 2000-01-05\n\nRead [the doc](http://example.com/doc), which is **mostly** done.\n")
+	if _, err := s.Reload(); err != nil {
+		t.Fatal(err)
+	}
+
+	_, body := get(t, s, "/reminders")
+	if strings.Contains(body, "2000-01-05") || strings.Contains(body, "Unknown node") {
+		t.Errorf("Expected the entry's markdown to be left out:\n%s", body)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	s, _, cleanup := newTestServer(t)
+	defer cleanup()
+
+	code, body := get(t, s, "/errors")
+	if code != http.StatusOK {
+		t.Fatalf("Got status %d", code)
+	}
+	assertContains(t, body,
+		`<h2><a href="/entries/2000-01-03">2000-01-03</a></h2>
+<ul>
+<li>Line 9: no valid spec parser found for spec &#34;someday&#34;</li>
+</ul>`,
+	)
+}
+
+func TestReload(t *testing.T) {
+	s, dir, cleanup := newTestServer(t)
+	defer cleanup()
+
+	if reloaded, err := s.Reload(); err != nil || reloaded {
+		t.Errorf("Reload() = %v, %v, want false, nil when nothing changed", reloaded, err)
+	}
+
+	writeEntry(t, dir, "2000-01-05.md", "# Andrew Allen - 2000-01-05\n")
+	if reloaded, err := s.Reload(); err != nil || !reloaded {
+		t.Errorf("Reload() = %v, %v, want true, nil after adding an entry", reloaded, err)
+	}
+	if _, body := get(t, s, "/"); !strings.Contains(body, "2000-01-05") {
+		t.Errorf("Expected the new entry to be listed:\n%s", body)
+	}
+}
+
+func TestReloadMissingLogbook(t *testing.T) {
+	dir, err := ioutil.TempDir("", "logbook")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	s := New(&config.Config{
+		Name:    "Andrew Allen",
+		LogPath: filepath.Join(dir, "logbook"),
+	})
+	if _, err := s.Reload(); err != nil {
+		t.Fatalf("Expected a logbook that doesn't exist yet to be empty, got %v", err)
+	}
+	if code, _ := get(t, s, "/"); code != http.StatusOK {
+		t.Errorf("Got status %d", code)
+	}
+
+	// It's picked up once it's created.
+	if err := os.Mkdir(filepath.Join(dir, "logbook"), 0700); err != nil {
+		t.Fatal(err)
+	}
+	writeEntry(t, filepath.Join(dir, "logbook"), "2000-01-05.md", "# Andrew Allen - 2000-01-05\n")
+	if reloaded, err := s.Reload(); err != nil || !reloaded {
+		t.Errorf("Reload() = %v, %v, want true, nil after creating the logbook", reloaded, err)
+	}
+}
+
+func TestWatch(t *testing.T) {
+	s, dir, cleanup := newTestServer(t)
+	defer cleanup()
+
+	stop := make(chan struct{})
+	defer close(stop)
+	go s.Watch(time.Millisecond, stop)
+
+	writeEntry(t, dir, "2000-01-05.md", "# Andrew Allen - 2000-01-05\n")
+	for i := 0; i < 1000; i++ {
+		if code, _ := get(t, s, "/entries/2000-01-05"); code == http.StatusOK {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Errorf("The new entry was never served")
+}
@@ -0,0 +1,151 @@
+/**
+ * Copyright 2018 Google LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the license.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specified language governing permissions and
+ * limitations under the License.
+ */
+package server
+
+import (
+	"html/template"
+
+	"github.com/achew22/logbook/parser"
+)
+
+type indexPage struct {
+	Title   string
+	Entries []*parser.LogEntry
+}
+
+type entryPage struct {
+	Title string
+	Entry *parser.LogEntry
+	HTML  template.HTML
+
+	// Previous and Next are the entries before and after this one, if
+	// there are any.
+	Previous *parser.LogEntry
+	Next     *parser.LogEntry
+}
+
+type remindersPage struct {
+	Title string
+	Days  []*reminderDay
+}
+
+type reminderDay struct {
+	Date   parser.Date
+	Groups []*reminderGroup
+}
+
+type reminderGroup struct {
+	Origin    parser.Date
+	Reminders []*parser.Reminder
+}
+
+type errorsPage struct {
+	Title   string
+	Entries []*parser.LogEntry
+}
+
+var funcs = template.FuncMap{
+	"ymd": func(d parser.Date) string {
+		return d.ToYmd()
+	},
+	"weekday": func(d parser.Date) string {
+		return d.ToTime().Weekday().String()
+	},
+}
+
+var pages = template.Must(template.New("pages").Funcs(funcs).Parse(`
+{{define "header" -}}
+<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: sans-serif; max-width: 50em; margin: 0 auto; padding: 1em; }
+nav a { margin-right: 1em; }
+</style>
+</head>
+<body>
+<nav><a href="/">Entries</a><a href="/reminders">Upcoming reminders</a><a href="/errors">Parse errors</a></nav>
+{{end}}
+
+{{define "footer" -}}
+</body>
+</html>
+{{end}}
+
+{{define "index" -}}
+{{template "header" .}}
+<h1>{{.Title}}</h1>
+{{if .Entries -}}
+<ul>
+{{range .Entries -}}
+<li><a href="/entries/{{ymd .Date}}">{{ymd .Date}}</a> {{weekday .Date}}</li>
+{{end -}}
+</ul>
+{{else -}}
+<p>There are no entries yet.</p>
+{{end -}}
+{{template "footer" .}}
+{{- end}}
+
+{{define "entry" -}}
+{{template "header" .}}
+<nav>
+{{- with .Previous}}<a href="/entries/{{ymd .Date}}">&larr; {{ymd .Date}}</a>{{end -}}
+{{- with .Next}}<a href="/entries/{{ymd .Date}}">{{ymd .Date}} &rarr;</a>{{end -}}
+</nav>
+<article>
+{{.HTML}}
+</article>
+{{template "footer" .}}
+{{- end}}
+
+{{define "reminders" -}}
+{{template "header" .}}
+<h1>{{.Title}}</h1>
+{{range .Days -}}
+<h2>{{ymd .Date}} {{weekday .Date}}</h2>
+{{range .Groups -}}
+<p>From {{ymd .Origin}}:</p>
+<ul>
+{{range .Reminders -}}
+<li>{{.}}</li>
+{{end -}}
+</ul>
+{{end -}}
+{{else -}}
+<p>There are no upcoming reminders.</p>
+{{end -}}
+{{template "footer" .}}
+{{- end}}
+
+{{define "errors" -}}
+{{template "header" .}}
+<h1>{{.Title}}</h1>
+{{range .Entries -}}
+<h2><a href="/entries/{{ymd .Date}}">{{ymd .Date}}</a></h2>
+<ul>
+{{range .Errors -}}
+<li>{{if .Line}}Line {{.Line}}: {{end}}{{.Message}}</li>
+{{end -}}
+</ul>
+{{else -}}
+<p>There are no parse errors.</p>
+{{end -}}
+{{template "footer" .}}
+{{- end}}
+`))
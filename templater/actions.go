@@ -0,0 +1,96 @@
+/**
+ * Copyright 2018 Google LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the license.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specified language governing permissions and
+ * limitations under the License.
+ */
+package templater
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/achew22/logbook/parser"
+)
+
+type ownedActionItem struct {
+	date parser.Date
+	item *parser.ActionItem
+}
+
+func actionItemKey(item *parser.ActionItem) string {
+	return strings.ToLower(item.Owner) + "\x00" + item.Text
+}
+
+// PrintActions lists every open action item in the logbook grouped by owner.
+// Owners are sorted alphabetically and their items oldest first.
+func PrintActions(entries map[parser.Date]*parser.LogEntry) string {
+	// An item is closed by checking it off in the entry it was written in or
+	// in any later one, so find when each item was last checked off.
+	lastDone := map[string]parser.Date{}
+	for d, entry := range entries {
+		for _, item := range entry.ActionItems {
+			key := actionItemKey(item)
+			if prev, ok := lastDone[key]; item.Done && (!ok || d.After(prev)) {
+				lastDone[key] = d
+			}
+		}
+	}
+
+	// Owners are grouped case insensitively since "AI(bob)" and "AI(Bob)" are
+	// the same person.
+	byOwner := map[string][]ownedActionItem{}
+	for d, entry := range entries {
+		for _, item := range entry.ActionItems {
+			if done, ok := lastDone[actionItemKey(item)]; ok && !done.Before(d) {
+				continue
+			}
+			key := strings.ToLower(item.Owner)
+			byOwner[key] = append(byOwner[key], ownedActionItem{
+				date: d,
+				item: item,
+			})
+		}
+	}
+
+	buf := &strings.Builder{}
+	if len(byOwner) == 0 {
+		fmt.Fprintf(buf, "There are no open action items\n")
+		return buf.String()
+	}
+
+	var owners []string
+	for owner := range byOwner {
+		owners = append(owners, owner)
+	}
+	sort.Strings(owners)
+
+	for _, owner := range owners {
+		items := byOwner[owner]
+		sort.SliceStable(items, func(i, j int) bool {
+			return items[i].date.Before(items[j].date)
+		})
+
+		name := items[0].item.Owner
+		if name == "" {
+			name = "Unassigned"
+		}
+		fmt.Fprintf(buf, "%s:\n\n", name)
+		for _, i := range items {
+			fmt.Fprintf(buf, " *  %s: %s\n", i.date.ToYmd(), i.item.Text)
+		}
+		fmt.Fprintf(buf, "\n")
+	}
+
+	return buf.String()
+}
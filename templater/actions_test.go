@@ -0,0 +1,70 @@
+package templater
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/achew22/logbook/parser"
+)
+
+func TestPrintActions(t *testing.T) {
+	got := strings.Split(trim(PrintActions(map[parser.Date]*parser.LogEntry{
+		ymd("2014-02-14"): &parser.LogEntry{
+			ActionItems: []*parser.ActionItem{
+				{Owner: "bob", Text: "Send the design doc"},
+				{Owner: "alice", Text: "Already done", Done: true},
+			},
+		},
+		ymd("2014-02-13"): &parser.LogEntry{
+			ActionItems: []*parser.ActionItem{
+				{Owner: "Bob", Text: "Book a room"},
+				{Owner: "alice", Text: "Review the launch"},
+			},
+		},
+	})), "\n")
+	want := strings.Split(trim(`
+alice:
+
+ *  2014-02-13: Review the launch
+
+Bob:
+
+ *  2014-02-13: Book a room
+ *  2014-02-14: Send the design doc`), "\n")
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("Differences:\n%s\nGot:  %q\nWant: %q", diff, got, want)
+	}
+}
+
+func TestPrintActionsNoneOpen(t *testing.T) {
+	got := trim(PrintActions(map[parser.Date]*parser.LogEntry{
+		ymd("2014-02-14"): &parser.LogEntry{
+			ActionItems: []*parser.ActionItem{
+				{Owner: "bob", Text: "Send the design doc", Done: true},
+			},
+		},
+	}))
+	if want := "There are no open action items"; got != want {
+		t.Errorf("Got %q, want %q", got, want)
+	}
+}
+
+func TestPrintActionsCheckedOffLater(t *testing.T) {
+	got := trim(PrintActions(map[parser.Date]*parser.LogEntry{
+		ymd("2014-02-13"): &parser.LogEntry{
+			ActionItems: []*parser.ActionItem{
+				{Owner: "bob", Text: "Send the design doc"},
+			},
+		},
+		ymd("2014-02-14"): &parser.LogEntry{
+			ActionItems: []*parser.ActionItem{
+				{Owner: "Bob", Text: "Send the design doc", Done: true},
+			},
+		},
+	}))
+	if want := "There are no open action items"; got != want {
+		t.Errorf("Got %q, want %q", got, want)
+	}
+}
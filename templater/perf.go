@@ -0,0 +1,62 @@
+/**
+ * Copyright 2018 Google LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the license.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specified language governing permissions and
+ * limitations under the License.
+ */
+package templater
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/achew22/logbook/parser"
+)
+
+// perfNotes returns every perf note written during q, oldest first.
+func perfNotes(entries map[parser.Date]*parser.LogEntry, q parser.Quarter) []*PerfNote {
+	var notes []*PerfNote
+	for d, entry := range entries {
+		if !q.Contains(d) {
+			continue
+		}
+		for _, note := range entry.PerfNotes {
+			notes = append(notes, &PerfNote{
+				Date: d,
+				Text: note.Text,
+			})
+		}
+	}
+
+	sort.SliceStable(notes, func(i, j int) bool {
+		return notes[i].Date.Before(notes[j].Date)
+	})
+	return notes
+}
+
+// PrintPerf lists every perf note written during q.
+func PrintPerf(entries map[parser.Date]*parser.LogEntry, q parser.Quarter) string {
+	buf := &strings.Builder{}
+
+	notes := perfNotes(entries, q)
+	if len(notes) == 0 {
+		fmt.Fprintf(buf, "There are no perf notes for %s\n", q)
+		return buf.String()
+	}
+
+	fmt.Fprintf(buf, "## Perf notes for %s\n\n", q)
+	for _, n := range notes {
+		fmt.Fprintf(buf, " *  %s: %s\n", n.Date.ToYmd(), n.Text)
+	}
+	return buf.String()
+}
@@ -0,0 +1,49 @@
+package templater
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/achew22/logbook/config"
+	"github.com/achew22/logbook/parser"
+)
+
+func TestPerfRollupWindow(t *testing.T) {
+	entries := map[parser.Date]*parser.LogEntry{
+		ymd("2014-02-14"): &parser.LogEntry{
+			PerfNotes: []*parser.PerfNote{
+				{Text: "Shipped the thing"},
+			},
+		},
+	}
+
+	tests := map[string]struct {
+		today      string
+		rollupDays int
+		want       bool
+	}{
+		"Outside the window":       {"2014-03-16", 14, false},
+		"First day of the window":  {"2014-03-17", 14, true},
+		"Last day of the quarter":  {"2014-03-31", 14, true},
+		"Next quarter":             {"2014-04-01", 14, false},
+		"Only on the last day":     {"2014-03-31", 0, true},
+		"Disabled":                 {"2014-03-31", -1, false},
+		"Whole quarter is covered": {"2014-01-01", 90, true},
+	}
+	for n, test := range tests {
+		t.Run(n, func(t *testing.T) {
+			c := &config.Config{
+				Name:           "Andrew Allen",
+				PerfRollupDays: test.rollupDays,
+			}
+			out, err := Print(c, entries, ymd(test.today))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := strings.Contains(out, "## Perf notes this quarter")
+			if got != test.want {
+				t.Errorf("Expected roll-up to be included: %t, was %t", test.want, got)
+			}
+		})
+	}
+}
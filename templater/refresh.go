@@ -0,0 +1,101 @@
+/**
+ * Copyright 2018 Google LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the license.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specified language governing permissions and
+ * limitations under the License.
+ */
+package templater
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+
+	"github.com/achew22/logbook/parser"
+)
+
+// checkboxFinder matches checklist items like " *  [x] Text". The groups are
+// everything up to the box, what's in the box, and the text.
+var checkboxFinder = regexp.MustCompile(`^(\s*[*+-]\s+\[)([ xX])(\]\s*(.*))$`)
+
+// keepChecked ticks the items in section that were ticked in old, matching
+// them on their text.
+func keepChecked(old, section string) string {
+	checked := map[string]string{}
+	for _, line := range strings.Split(old, "\n") {
+		if m := checkboxFinder.FindStringSubmatch(line); m != nil && m[2] != " " {
+			checked[strings.TrimSpace(m[4])] = m[2]
+		}
+	}
+
+	lines := strings.Split(section, "\n")
+	for i, line := range lines {
+		m := checkboxFinder.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		if box, ok := checked[strings.TrimSpace(m[4])]; ok {
+			lines[i] = m[1] + box + m[3]
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// generatedSection returns where the section between parser.GeneratedBegin
+// and parser.GeneratedEnd, including the markers, starts and ends in text.
+// start is -1 if there isn't one.
+func generatedSection(text string) (start, end int, err error) {
+	start = strings.Index(text, parser.GeneratedBegin)
+	if start < 0 {
+		return -1, -1, nil
+	}
+	end = strings.Index(text[start:], parser.GeneratedEnd)
+	if end < 0 {
+		return -1, -1, errors.New("the generated section is missing its end marker " + parser.GeneratedEnd)
+	}
+	return start, start + end + len(parser.GeneratedEnd), nil
+}
+
+// Refresh replaces the generated section of an existing entry with the one in
+// generated, keeping everything else that was written in the entry. If the
+// entry doesn't have a generated section, it is added after the heading.
+// Items that were ticked in the old section stay ticked.
+func Refresh(existing, generated string) (string, error) {
+	start, end, err := generatedSection(generated)
+	if err != nil {
+		return "", err
+	}
+	if start < 0 {
+		return "", errors.New("the template doesn't have a generated section to refresh")
+	}
+	section := generated[start:end]
+
+	start, end, err = generatedSection(existing)
+	if err != nil {
+		return "", err
+	}
+	if start >= 0 {
+		return existing[:start] + keepChecked(existing[start:end], section) + existing[end:], nil
+	}
+
+	// Entries written by hand usually start with a heading, which the
+	// generated section goes after.
+	if strings.HasPrefix(existing, "# ") {
+		heading := existing
+		rest := ""
+		if i := strings.Index(existing, "\n"); i >= 0 {
+			heading, rest = existing[:i], existing[i+1:]
+		}
+		return heading + "\n\n" + section + "\n\n" + strings.TrimLeft(rest, "\n"), nil
+	}
+	return section + "\n\n" + existing, nil
+}
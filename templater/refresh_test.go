@@ -0,0 +1,187 @@
+package templater
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRefresh(t *testing.T) {
+	generated := `# Andrew Allen - 2000-01-02
+
+<!-- logbook:begin -->
+
+## Reminders:
+
+From 2000-01-01:
+
+ *  New reminder
+
+<!-- logbook:end -->
+
+`
+
+	tests := map[string]struct {
+		existing string
+		want     string
+	}{
+		"replaces the generated section": {
+			existing: `# My own heading
+
+<!-- logbook:begin -->
+
+There are no reminders for today
+
+<!-- logbook:end -->
+
+Notes I wrote.
+`,
+			want: `# My own heading
+
+<!-- logbook:begin -->
+
+## Reminders:
+
+From 2000-01-01:
+
+ *  New reminder
+
+<!-- logbook:end -->
+
+Notes I wrote.
+`,
+		},
+		"adds the generated section after the heading": {
+			existing: `# Andrew Allen - 2000-01-02
+Notes I wrote.
+`,
+			want: `# Andrew Allen - 2000-01-02
+
+<!-- logbook:begin -->
+
+## Reminders:
+
+From 2000-01-01:
+
+ *  New reminder
+
+<!-- logbook:end -->
+
+Notes I wrote.
+`,
+		},
+		"adds the generated section without a heading": {
+			existing: "Notes I wrote.\n",
+			want: `<!-- logbook:begin -->
+
+## Reminders:
+
+From 2000-01-01:
+
+ *  New reminder
+
+<!-- logbook:end -->
+
+Notes I wrote.
+`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := Refresh(test.existing, generated)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(got, test.want); diff != "" {
+				t.Errorf("Differences:\n%s\nGot:  %q\nWant: %q", diff, got, test.want)
+			}
+		})
+	}
+}
+
+func TestRefreshKeepsChecked(t *testing.T) {
+	existing := `# Andrew Allen - 2000-01-04
+
+<!-- logbook:begin -->
+
+## TODO
+
+ *  [x] Write the report
+ *  [ ] Send the report
+
+## Open items:
+
+ *  [X] Book the room (since 2000-01-03)
+
+<!-- logbook:end -->
+
+ *  [x] Something I wrote myself
+`
+	generated := `<!-- logbook:begin -->
+
+## TODO
+
+ *  [ ] Write the report
+ *  [ ] Send the report
+ *  [ ] Something I wrote myself
+
+## Open items:
+
+ *  [ ] Book the room (since 2000-01-03)
+ *  [ ] Order lunch (since 2000-01-03)
+
+<!-- logbook:end -->
+`
+	want := `# Andrew Allen - 2000-01-04
+
+<!-- logbook:begin -->
+
+## TODO
+
+ *  [x] Write the report
+ *  [ ] Send the report
+ *  [ ] Something I wrote myself
+
+## Open items:
+
+ *  [X] Book the room (since 2000-01-03)
+ *  [ ] Order lunch (since 2000-01-03)
+
+<!-- logbook:end -->
+
+ *  [x] Something I wrote myself
+`
+
+	got, err := Refresh(existing, generated)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("Differences:\n%s\nGot:  %q\nWant: %q", diff, got, want)
+	}
+}
+
+func TestRefreshErrors(t *testing.T) {
+	tests := map[string]struct {
+		existing  string
+		generated string
+	}{
+		"template without markers": {
+			existing:  "# Andrew Allen - 2000-01-02\n",
+			generated: "# Andrew Allen - 2000-01-02\n",
+		},
+		"missing end marker": {
+			existing:  "# Andrew Allen - 2000-01-02\n\n<!-- logbook:begin -->\n\nNotes\n",
+			generated: "<!-- logbook:begin -->\n<!-- logbook:end -->\n",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got, err := Refresh(test.existing, test.generated); err == nil {
+				t.Errorf("Expected an error, got %q", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,138 @@
+/**
+ * Copyright 2018 Google LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the license.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specified language governing permissions and
+ * limitations under the License.
+ */
+package templater
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"text/template"
+
+	"github.com/achew22/logbook/config"
+	"github.com/achew22/logbook/parser"
+)
+
+// DefaultTemplate is used to generate entries unless the config has a
+// TemplatePath. It is executed with a *Data. Everything between
+// parser.GeneratedBegin and parser.GeneratedEnd is replaced when an entry is
+// refreshed.
+const DefaultTemplate = `# {{.Name}} - {{ymd .Today}}
+
+` + parser.GeneratedBegin + `
+
+{{if or .Reminders .Missed -}}
+## Reminders:
+
+{{range .Reminders -}}
+From {{ymd .Origin}}:
+
+{{range .Reminders}} *  {{.Text}}{{with location .Position}} ({{.}}){{end}}
+{{end}}
+{{end -}}
+{{range .Missed -}}
+Missed from {{ymd .Date}}:
+
+{{range .Reminders}}{{range .Reminders}} *  {{.Text}}{{with location .Position}} ({{.}}){{end}}
+{{end}}{{end}}
+{{end -}}
+{{else -}}
+There are no reminders for today
+
+{{end -}}
+{{if .Todos -}}
+## TODO
+
+{{range .Todos}} *  [ ] {{.Text}}
+{{end}}
+{{end -}}
+{{if .OpenItems -}}
+## Open items:
+
+{{range .OpenItems}} *  [ ] {{.Text}} (since {{ymd .Since}})
+{{end}}
+{{end -}}
+{{if .PerfNotes -}}
+## Perf notes this quarter
+
+{{range .PerfNotes}} *  {{ymd .Date}}: {{.Text}}
+{{end}}
+{{end -}}
+{{if .Errors -}}
+## Parse errors
+
+{{range .Errors -}}
+From {{ymd .Date}}:
+
+{{range .Errors}} *  {{with location .Position}}{{.}}: {{end}}{{.Message}}
+{{end}}
+{{end -}}
+{{end -}}
+` + parser.GeneratedEnd + `
+
+`
+
+// funcs are the helpers available to templates.
+func funcs(c *config.Config, today parser.Date) template.FuncMap {
+	return template.FuncMap{
+		// ymd formats a date as "2006-01-02".
+		"ymd": func(d parser.Date) string {
+			return d.ToYmd()
+		},
+		// weekday returns the name of the day of the week of a date.
+		"weekday": func(d parser.Date) string {
+			return d.ToTime().Weekday().String()
+		},
+		// daysAgo returns how many days before today a date is.
+		"daysAgo": func(d parser.Date) int {
+			return d.DaysUntil(today)
+		},
+		// location formats a position like "2006-01-02.md:14", relative to
+		// the logbook.
+		"location": func(pos parser.Position) string {
+			return Location(c, pos)
+		},
+	}
+}
+
+func loadTemplate(c *config.Config, today parser.Date) (*template.Template, error) {
+	name, text := "default", DefaultTemplate
+	if c.TemplatePath != "" {
+		b, err := ioutil.ReadFile(c.TemplatePath)
+		if err != nil {
+			return nil, err
+		}
+		name, text = c.TemplatePath, string(b)
+	}
+
+	return template.New(name).Funcs(funcs(c, today)).Parse(text)
+}
+
+// Location formats pos like "2006-01-02.md:14", with the path relative to the
+// logbook. It is empty if pos doesn't have a path.
+func Location(c *config.Config, pos parser.Position) string {
+	if pos.Path == "" {
+		return ""
+	}
+
+	path := pos.Path
+	if rel, err := filepath.Rel(c.LogPath, path); err == nil {
+		path = rel
+	}
+	if pos.Line == 0 {
+		return path
+	}
+	return fmt.Sprintf("%s:%d", path, pos.Line)
+}
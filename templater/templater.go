@@ -16,58 +16,163 @@
 package templater
 
 import (
-	"fmt"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/achew22/logbook/config"
 	"github.com/achew22/logbook/parser"
 )
 
-type extractedEntry struct {
-	originDate parser.Date
-	text       string
+// Data is what templates are executed with.
+type Data struct {
+	Name    string
+	Today   parser.Date
+	Weekday time.Weekday
+
+	// Previous is the last entry before today that is on disk, or nil if this
+	// is the first one.
+	Previous *parser.LogEntry
+
+	// Reminders are the reminders for today grouped by the date they were
+	// written on. Like Missed and Errors, they are oldest first unless
+	// config.Config.NewestFirst is set.
+	Reminders []*ReminderGroup
+
+	// Missed are the reminders for the days between the previous entry and
+	// today, which would otherwise never be seen.
+	Missed []*MissedDay
+
+	// Todos are the TODOs written in the previous entry.
+	Todos []*parser.Todo
+
+	// OpenItems are the unchecked checklist items in the previous entry.
+	OpenItems []*parser.OpenItem
+
+	// Quarter is the quarter today is in.
+	Quarter parser.Quarter
+
+	// PerfNotes are the perf notes written during Quarter. It is only set
+	// near the end of the quarter, see config.Config.PerfRollupDays.
+	PerfNotes []*PerfNote
+
+	// Errors are every parse error in the logbook grouped by the date of the
+	// entry they were found in.
+	Errors []*ErrorGroup
 }
 
-func Print(c *config.Config, entries map[parser.Date]*parser.LogEntry, today parser.Date) string {
-	buf := &strings.Builder{}
+type ReminderGroup struct {
+	Origin    parser.Date
+	Reminders []*parser.Reminder
+}
+
+type MissedDay struct {
+	Date      parser.Date
+	Reminders []*ReminderGroup
+}
+
+type PerfNote struct {
+	Date parser.Date
+	Text string
+}
+
+type ErrorGroup struct {
+	Date   parser.Date
+	Errors []*parser.ParseError
+}
+
+func nextDay(d parser.Date) parser.Date {
+	return parser.TimeToDate(d.ToTime().AddDate(0, 0, 1))
+}
 
-	fmt.Fprintf(buf, "# %s - %s\n\n", c.Name, today.ToYmd())
+// chronological reports whether a should be listed before b, which is oldest
+// first unless the config asks for newest first.
+func chronological(c *config.Config, a, b parser.Date) bool {
+	if c.NewestFirst {
+		return a.After(b)
+	}
+	return a.Before(b)
+}
 
-	todayLog, ok := entries[today]
-	if !ok {
-		fmt.Fprintf(buf, "There are no reminders for today\n\n")
-	} else {
-		if len(todayLog.PastReferences) > 0 {
-			fmt.Fprintf(buf, "## Reminders:\n\n")
+func reminderGroups(c *config.Config, reminders map[parser.Date][]*parser.Reminder) []*ReminderGroup {
+	var groups []*ReminderGroup
+	for origin, messages := range reminders {
+		groups = append(groups, &ReminderGroup{
+			Origin:    origin,
+			Reminders: messages,
+		})
+	}
+
+	sort.Slice(groups, func(i, j int) bool {
+		return chronological(c, groups[i].Origin, groups[j].Origin)
+	})
+	return groups
+}
 
-			for originDate, messages := range todayLog.PastReferences {
-				fmt.Fprintf(buf, "From %s:\n\n", originDate.ToYmd())
-				for _, m := range messages {
-					fmt.Fprintf(buf, " *  %s\n", m)
-				}
+// NewData collects everything that goes into the entry for today.
+func NewData(c *config.Config, entries map[parser.Date]*parser.LogEntry, today parser.Date) *Data {
+	data := &Data{
+		Name:     c.Name,
+		Today:    today,
+		Weekday:  today.ToTime().Weekday(),
+		Previous: parser.PreviousEntry(entries, today),
+		Quarter:  parser.QuarterOf(today),
+	}
+
+	data.Reminders = reminderGroups(c, parser.RemindersOn(entries, today))
+
+	if data.Previous != nil {
+		for d := nextDay(data.Previous.Date); d.Before(today); d = nextDay(d) {
+			if reminders := parser.RemindersOn(entries, d); len(reminders) > 0 {
+				data.Missed = append(data.Missed, &MissedDay{
+					Date:      d,
+					Reminders: reminderGroups(c, reminders),
+				})
 			}
 		}
-		fmt.Fprintf(buf, "\n")
+
+		// Since TODOs are rendered as a checklist, anything left unchecked
+		// is carried forward as an open item afterwards.
+		data.Todos = data.Previous.Todos
+		data.OpenItems = data.Previous.OpenItems
+	}
+
+	// Around the end of the quarter, collect everything worth bringing up
+	// during performance reviews.
+	if c.PerfRollupDays >= 0 && today.DaysUntil(data.Quarter.End()) <= c.PerfRollupDays {
+		data.PerfNotes = perfNotes(entries, data.Quarter)
 	}
 
-	parseErrors := map[parser.Date][]*parser.ParseError{}
 	for d, entry := range entries {
 		if len(entry.Errors) > 0 {
-			parseErrors[d] = entry.Errors
+			data.Errors = append(data.Errors, &ErrorGroup{
+				Date:   d,
+				Errors: entry.Errors,
+			})
 		}
 	}
 
-	if len(parseErrors) > 0 {
-		fmt.Fprintf(buf, "## Parse errors\n\n")
-		for d, errors := range parseErrors {
-			fmt.Fprintf(buf, "From %s:\n\n", d.ToYmd())
-			for _, err := range errors {
-				fmt.Fprintf(buf, " *  %s\n", err.Message)
-			}
-			fmt.Fprintf(buf, "\n")
-		}
+	sort.Slice(data.Missed, func(i, j int) bool {
+		return chronological(c, data.Missed[i].Date, data.Missed[j].Date)
+	})
+	sort.Slice(data.Errors, func(i, j int) bool {
+		return chronological(c, data.Errors[i].Date, data.Errors[j].Date)
+	})
+
+	return data
+}
+
+// Print renders the entry for today with the template at c.TemplatePath, or
+// the DefaultTemplate if there isn't one.
+func Print(c *config.Config, entries map[parser.Date]*parser.LogEntry, today parser.Date) (string, error) {
+	tmpl, err := loadTemplate(c, today)
+	if err != nil {
+		return "", err
 	}
 
-	fmt.Fprintf(buf, "\n")
-	return buf.String()
+	buf := &strings.Builder{}
+	if err := tmpl.Execute(buf, NewData(c, entries, today)); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
 }
@@ -5,6 +5,9 @@ package templater
 
 import (
 	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -30,20 +33,238 @@ func trim(s string) string {
 	return strings.Trim(s, " \n\t")
 }
 
+func mustPrint(t *testing.T, c *config.Config, entries map[parser.Date]*parser.LogEntry, today parser.Date) string {
+	out, err := Print(c, entries, today)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
 func TestSimpleTemplating(t *testing.T) {
 	c := &config.Config{
 		Name: "Andrew Allen",
 	}
 	today := ymd("2014-02-14")
 
-	got := strings.Split(strings.Trim(Print(c, map[parser.Date]*parser.LogEntry{
+	got := strings.Split(strings.Trim(mustPrint(t, c, map[parser.Date]*parser.LogEntry{
 		ymd("2014-02-13"): &parser.LogEntry{},
 	}, today), " \t\n"), "\n")
 	want := strings.Split(strings.Trim(`
 # Andrew Allen - 2014-02-14
 
-There are no reminders for today`, " \t\n"), "\n")
+<!-- logbook:begin -->
+
+There are no reminders for today
+
+<!-- logbook:end -->`, " \t\n"), "\n")
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("Differences:\n%s\nGot:  %q\nWant: %q", diff, got, want)
+	}
+}
+
+func TestOpenItemsCarriedForward(t *testing.T) {
+	c := &config.Config{
+		Name: "Andrew Allen",
+	}
+	today := ymd("2014-02-17")
+
+	got := strings.Split(trim(mustPrint(t, c, map[parser.Date]*parser.LogEntry{
+		ymd("2014-02-13"): &parser.LogEntry{
+			Date:   ymd("2014-02-13"),
+			OnDisk: true,
+			OpenItems: []*parser.OpenItem{
+				{Since: ymd("2014-02-13"), Text: "Stale item"},
+			},
+		},
+		ymd("2014-02-14"): &parser.LogEntry{
+			Date:   ymd("2014-02-14"),
+			OnDisk: true,
+			OpenItems: []*parser.OpenItem{
+				{Since: ymd("2014-02-14"), Text: "Write the doc"},
+				{Since: ymd("2014-02-10"), Text: "Old item"},
+			},
+		},
+	}, today)), "\n")
+	want := strings.Split(trim(`
+# Andrew Allen - 2014-02-17
+
+<!-- logbook:begin -->
+
+There are no reminders for today
+
+## Open items:
+
+ *  [ ] Write the doc (since 2014-02-14)
+ *  [ ] Old item (since 2014-02-10)
+
+<!-- logbook:end -->`), "\n")
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("Differences:\n%s\nGot:  %q\nWant: %q", diff, got, want)
+	}
+}
+
+func TestNewestFirst(t *testing.T) {
+	c := &config.Config{
+		Name:        "Andrew Allen",
+		NewestFirst: true,
+	}
+	today := ymd("2014-02-14")
+
+	got := strings.Split(trim(mustPrint(t, c, map[parser.Date]*parser.LogEntry{
+		ymd("2014-02-14"): &parser.LogEntry{
+			Date: ymd("2014-02-14"),
+			PastReferences: map[parser.Date][]*parser.Reminder{
+				ymd("2014-02-10"): {{Text: "Oldest"}},
+				ymd("2014-02-13"): {{Text: "Newest"}},
+				ymd("2014-02-11"): {{Text: "Middle"}},
+			},
+		},
+		ymd("2014-02-10"): &parser.LogEntry{
+			Date:   ymd("2014-02-10"),
+			Errors: []*parser.ParseError{{Message: "Oldest error"}},
+		},
+		ymd("2014-02-13"): &parser.LogEntry{
+			Date:   ymd("2014-02-13"),
+			Errors: []*parser.ParseError{{Message: "Newest error"}},
+		},
+	}, today)), "\n")
+	want := strings.Split(trim(`
+# Andrew Allen - 2014-02-14
+
+<!-- logbook:begin -->
+
+## Reminders:
+
+From 2014-02-13:
+
+ *  Newest
+
+From 2014-02-11:
+
+ *  Middle
+
+From 2014-02-10:
+
+ *  Oldest
+
+## Parse errors
+
+From 2014-02-13:
+
+ *  Newest error
+
+From 2014-02-10:
+
+ *  Oldest error
+
+<!-- logbook:end -->`), "\n")
+	if diff := cmp.Diff(got, want); diff != "" {
+		t.Errorf("Differences:\n%s\nGot:  %q\nWant: %q", diff, got, want)
+	}
+}
+
+func TestLocations(t *testing.T) {
+	c := &config.Config{
+		Name:    "Andrew Allen",
+		LogPath: "/logbook",
+	}
+	today := ymd("2014-02-14")
+
+	got := strings.Split(trim(mustPrint(t, c, map[parser.Date]*parser.LogEntry{
+		ymd("2014-02-14"): &parser.LogEntry{
+			Date: ymd("2014-02-14"),
+			PastReferences: map[parser.Date][]*parser.Reminder{
+				ymd("2014-02-13"): {
+					{Text: "Buy flowers", Position: parser.Position{Path: "/logbook/2014-02-13.md", Line: 3, Column: 1}},
+					{Text: "Standup", Position: parser.Position{Path: "/logbook/calendars/work.ics"}},
+				},
+			},
+		},
+		ymd("2014-02-13"): &parser.LogEntry{
+			Date: ymd("2014-02-13"),
+			Errors: []*parser.ParseError{
+				{Message: "Unknown", Position: parser.Position{Path: "/logbook/2014-02-13.md", Line: 5, Column: 1}},
+			},
+		},
+	}, today)), "\n")
+	want := strings.Split(trim(`
+# Andrew Allen - 2014-02-14
+
+<!-- logbook:begin -->
+
+## Reminders:
+
+From 2014-02-13:
+
+ *  Buy flowers (2014-02-13.md:3)
+ *  Standup (calendars/work.ics)
+
+## Parse errors
+
+From 2014-02-13:
+
+ *  2014-02-13.md:5: Unknown
+
+<!-- logbook:end -->`), "\n")
 	if diff := cmp.Diff(got, want); diff != "" {
 		t.Errorf("Differences:\n%s\nGot:  %q\nWant: %q", diff, got, want)
 	}
 }
+
+func TestUserTemplate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "logbook")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	templatePath := filepath.Join(dir, "template.md")
+	err = ioutil.WriteFile(templatePath, []byte(`{{.Name}} on {{.Weekday}}
+{{range .Reminders}}{{range .Reminders}}{{.}} ({{daysAgo $.Previous.Date}} days ago, a {{weekday $.Previous.Date}})
+{{end}}{{end}}`), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := &config.Config{
+		Name:         "Andrew Allen",
+		TemplatePath: templatePath,
+	}
+	got := mustPrint(t, c, map[parser.Date]*parser.LogEntry{
+		ymd("2014-02-13"): &parser.LogEntry{
+			Date:   ymd("2014-02-13"),
+			OnDisk: true,
+		},
+		ymd("2014-02-14"): &parser.LogEntry{
+			PastReferences: map[parser.Date][]*parser.Reminder{
+				ymd("2014-02-13"): {{Text: "Buy flowers"}},
+			},
+		},
+	}, ymd("2014-02-14"))
+
+	want := "Andrew Allen on Friday\nBuy flowers (1 days ago, a Thursday)\n"
+	if got != want {
+		t.Errorf("Got %q, want %q", got, want)
+	}
+}
+
+func TestInvalidUserTemplate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "logbook")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	templatePath := filepath.Join(dir, "template.md")
+	if err := ioutil.WriteFile(templatePath, []byte(`{{.Name`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	c := &config.Config{
+		TemplatePath: templatePath,
+	}
+	if _, err := Print(c, map[parser.Date]*parser.LogEntry{}, ymd("2014-02-14")); err == nil {
+		t.Errorf("Expected an invalid template to be an error")
+	}
+}